@@ -168,6 +168,12 @@ func New{{.TypeName}}(t godouble.T,configurators ...func(*godouble.TestDouble))
     result.TestDouble = godouble.NewDouble(t,(*{{packager .Type}})(nil), configurators...)
     return result
 }    
+
+func init() {
+    godouble.RegisterDouble((*{{packager .Type}})(nil), func(d *godouble.TestDouble) interface{} {
+        return &{{.TypeName}}{TestDouble: d}
+    })
+}
 {{end}}
 `
 const MethodTemplate = `
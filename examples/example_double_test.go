@@ -1,5 +1,5 @@
 // Code generated by go doublegen; DO NOT EDIT.
-// This file was generated at 2020-02-15T20:35:03+11:00
+// This file was generated at 2026-10-18T12:44:37Z
 
 // Package examples provides a TestDouble implementation of examples.API
 package examples
@@ -17,6 +17,12 @@ func NewAPIDouble(t godouble.T, configurators ...func(*godouble.TestDouble)) *AP
 	return result
 }
 
+func init() {
+	godouble.RegisterDouble((*API)(nil), func(d *godouble.TestDouble) interface{} {
+		return &APIDouble{TestDouble: d}
+	})
+}
+
 func (d *APIDouble) QueryWithOptions(i0 int, i1 ...string) (r0 *Results) {
 	d.TestDouble.T().Helper()
 	returns := d.TestDouble.Invoke("QueryWithOptions", i0, i1)
@@ -137,3 +137,20 @@ func Test_Unexported(t *testing.T) {
 		t.Errorf("Expected '1', Got %d", int(r))
 	}
 }
+
+func Test_Golden(t *testing.T) {
+	//Setup
+	transcript := NewTranscript()
+	d := NewAPIDouble(t, transcript.Record)
+	d.Stub("SomeQuery").Returning(Values(Results{"result"}, nil))
+	d.Stub("SomeCommand")
+	d.Stub("QueryWithOptions")
+
+	//Exercise
+	_, _ = d.SomeQuery("test")
+	d.SomeCommand()
+	d.QueryWithOptions(10, "hello", "golden")
+
+	//Verify the calls match testdata/Test_Golden.golden (GODOUBLE_UPDATE=true go test to rewrite it)
+	transcript.VerifyGolden(t)
+}
@@ -0,0 +1,272 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const arbitraryMaxDepth = 4 //nesting beyond which recursive types are left as zero values
+
+var arbitraryEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) //generated times are within a year of this
+
+type arbitraryOptions struct {
+	generators map[reflect.Type]reflect.Value
+	minSize    int
+	maxSize    int
+	errorRate  float64
+	err        error //invalid option, reported by ForMethod
+}
+
+// ArbitraryOption modifies how Arbitrary generates values
+type ArbitraryOption struct {
+	desc  string
+	apply func(o *arbitraryOptions)
+}
+
+func (ao ArbitraryOption) String() string {
+	return ao.desc
+}
+
+/*
+Generator generates values of type T with generate, which must be a func(Random) T. eg
+ Generator(func(r Random) Status { return Status(r.Int63n(3)) })
+*/
+func Generator(generate interface{}) ArbitraryOption {
+	return ArbitraryOption{fmt.Sprintf("Generator(%T)", generate), func(o *arbitraryOptions) {
+		gv := reflect.ValueOf(generate)
+		gt := gv.Type()
+		if gt.Kind() != reflect.Func || gt.NumIn() != 1 || gt.In(0) != reflect.TypeOf((*Random)(nil)).Elem() || gt.NumOut() != 1 {
+			o.err = fmt.Errorf("Generator expected func(Random) T, got %v", gt)
+			return
+		}
+		o.generators[gt.Out(0)] = gv
+	}}
+}
+
+// SizeBetween bounds the length of generated strings, slices and maps. The default is between 0 and 8
+func SizeBetween(min int, max int) ArbitraryOption {
+	return ArbitraryOption{fmt.Sprintf("SizeBetween(%d,%d)", min, max), func(o *arbitraryOptions) {
+		if min < 0 || max < min {
+			o.err = fmt.Errorf("SizeBetween expected 0 <= min <= max, got %d,%d", min, max)
+			return
+		}
+		o.minSize, o.maxSize = min, max
+	}}
+}
+
+// ArbitraryErrors returns a non nil error result, with zero values for other results, with probability p.
+// The default is to never return errors.
+func ArbitraryErrors(p float64) ArbitraryOption {
+	return ArbitraryOption{fmt.Sprintf("ArbitraryErrors(%v)", p), func(o *arbitraryOptions) {
+		o.errorRate = p
+	}}
+}
+
+type arbitraryReturnValues struct {
+	mutex   *sync.Mutex //Random sources are not safe for concurrent use
+	random  Random
+	options arbitraryOptions
+	opts    []ArbitraryOption
+	outputs []reflect.Type
+	errors  int
+}
+
+/*
+Arbitrary generates random values of the correct type for each of a method's results, for property style testing.
+
+Values are generated by walking the result types. Numbers, bools, strings and time.Time are random. Slices, maps, arrays,
+pointers and exported struct fields are filled recursively. Interfaces, funcs, channels and unexported fields are
+left as zero values, unless a Generator is provided for their type. eg
+ Arbitrary(Seed(seed), SizeBetween(1, 3), ArbitraryErrors(0.1),
+   Generator(func(r Random) Status { return Status(r.Int63n(3)) }))
+
+Pass an explicitly seeded Random so that failures are reproducible. See CheckProperty
+*/
+func Arbitrary(random Random, opts ...ArbitraryOption) ReturnValues {
+	a := &arbitraryReturnValues{
+		mutex:   &sync.Mutex{},
+		random:  random,
+		options: arbitraryOptions{generators: map[reflect.Type]reflect.Value{}, maxSize: 8},
+		opts:    opts,
+	}
+	for _, opt := range opts {
+		opt.apply(&a.options)
+	}
+	return a
+}
+
+func (a *arbitraryReturnValues) String() string {
+	desc := make([]string, len(a.opts))
+	for i, opt := range a.opts {
+		desc[i] = opt.String()
+	}
+	return fmt.Sprintf("Arbitrary(%s)", strings.Join(desc, ","))
+}
+
+func (a *arbitraryReturnValues) ForMethod(t T, m reflect.Method) {
+	t.Helper()
+	if a.options.err != nil {
+		t.Fatalf("%v for %v: %v", a, m.Type, a.options.err)
+	}
+	a.outputs = make([]reflect.Type, m.Type.NumOut())
+	for i := range a.outputs {
+		a.outputs[i] = m.Type.Out(i)
+	}
+}
+
+func (a *arbitraryReturnValues) Receive() ([]interface{}, error) {
+	if a.outputs == nil {
+		return nil, errors.New("arbitrary values have not been configured for a method")
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	results := make([]interface{}, len(a.outputs))
+	if last := len(a.outputs) - 1; last >= 0 && a.outputs[last] == errorType && a.random.Float64() < a.options.errorRate {
+		a.errors++
+		for i, out := range a.outputs[:last] {
+			results[i] = reflect.Zero(out).Interface()
+		}
+		results[last] = fmt.Errorf("arbitrary error %d", a.errors)
+		return results, nil
+	}
+
+	for i, out := range a.outputs {
+		results[i] = a.generate(out, 0).Interface()
+	}
+	return results, nil
+}
+
+func (a *arbitraryReturnValues) size() int {
+	return a.options.minSize + int(a.random.Int63n(int64(a.options.maxSize-a.options.minSize+1)))
+}
+
+// signed generates a random value that fits in a signed integer of bits
+func (a *arbitraryReturnValues) signed(bits int) int64 {
+	n := a.random.Int63n(int64(math.MaxInt64 >> uint(64-bits)))
+	if a.random.Float64() < 0.5 {
+		return -n
+	}
+	return n
+}
+
+func (a *arbitraryReturnValues) generate(t reflect.Type, depth int) reflect.Value {
+	if generator, found := a.options.generators[t]; found {
+		return generator.Call([]reflect.Value{reflect.ValueOf(a.random)})[0]
+	}
+	if t == timeType {
+		return reflect.ValueOf(arbitraryEpoch.Add(time.Duration(a.signed(64) % int64(365*24*time.Hour))))
+	}
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(a.random.Float64() < 0.5)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(a.signed(t.Bits()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(a.signed(t.Bits())))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat((a.random.Float64()*2 - 1) * 1e6)
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex((a.random.Float64()*2-1)*1e6, (a.random.Float64()*2-1)*1e6))
+	case reflect.String:
+		runes := make([]rune, a.size())
+		for i := range runes {
+			runes[i] = rune(' ' + a.random.Int63n('~'-' '+1)) //printable ascii
+		}
+		v.SetString(string(runes))
+	case reflect.Slice:
+		if depth < arbitraryMaxDepth {
+			size := a.size()
+			v.Set(reflect.MakeSlice(t, size, size))
+			for i := 0; i < size; i++ {
+				v.Index(i).Set(a.generate(t.Elem(), depth+1))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < t.Len() && depth < arbitraryMaxDepth; i++ {
+			v.Index(i).Set(a.generate(t.Elem(), depth+1))
+		}
+	case reflect.Map:
+		if depth < arbitraryMaxDepth {
+			v.Set(reflect.MakeMap(t))
+			for i, size := 0, a.size(); i < size; i++ {
+				v.SetMapIndex(a.generate(t.Key(), depth+1), a.generate(t.Elem(), depth+1))
+			}
+		}
+	case reflect.Ptr:
+		if depth < arbitraryMaxDepth {
+			v.Set(reflect.New(t.Elem()))
+			v.Elem().Set(a.generate(t.Elem(), depth+1))
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if field := v.Field(i); field.CanSet() {
+				field.Set(a.generate(t.Field(i).Type, depth+1))
+			}
+		}
+	}
+	return v
+}
+
+type seededT struct {
+	T
+	seed   int64
+	failed bool
+}
+
+func (s *seededT) Errorf(format string, args ...interface{}) {
+	s.T.Helper()
+	s.failed = true
+	s.T.Errorf("seed %d: %s", s.seed, fmt.Sprintf(format, args...))
+}
+
+func (s *seededT) Fatalf(format string, args ...interface{}) {
+	s.T.Helper()
+	s.failed = true
+	s.T.Fatalf("seed %d: %s", s.seed, fmt.Sprintf(format, args...))
+}
+
+/*
+CheckProperty runs property for n seeds, counting up from seed, stopping at the first seed for which property fails t.
+
+Failures are reported with their seed, so they can be reproduced by checking just that seed. eg
+ CheckProperty(t, 100, 1, func(t T, seed int64) {
+   d := NewAPIDouble(t)
+   d.Stub("SomeQuery").Returning(Arbitrary(Seed(seed), ArbitraryErrors(0.2)))
+   // exercise and assert with t
+ })
+*/
+func CheckProperty(t T, n int, seed int64, property func(t T, seed int64)) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		st := &seededT{T: t, seed: seed + int64(i)}
+		property(st, st.seed)
+		if st.failed {
+			t.Logf("property failed for seed %d, after %d successful seeds", st.seed, i)
+			return
+		}
+	}
+}
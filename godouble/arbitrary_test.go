@@ -0,0 +1,160 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"reflect"
+	"testing"
+)
+
+type accounts interface {
+	account(id int) (testAccount, error)
+	owners() map[string]*testUser
+}
+
+func accountsMethod(name string) reflect.Method {
+	m, _ := reflect.TypeOf((*accounts)(nil)).Elem().MethodByName(name)
+	return m
+}
+
+func receiveAccount(t *testing.T, rv ReturnValues) (testAccount, error) {
+	t.Helper()
+	returns, err := rv.Receive()
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	account, _ := returns[0].(testAccount)
+	accountErr, _ := returns[1].(error)
+	return account, accountErr
+}
+
+func TestArbitrary(t *testing.T) {
+	rv := NewReturnsForMethod(t, accountsMethod("account"), Arbitrary(Seed(1), SizeBetween(2, 2)))
+	for i := 0; i < 20; i++ {
+		account, err := receiveAccount(t, rv)
+		if err != nil {
+			t.Errorf("Expected no errors by default, got %v", err)
+		}
+		if len(account.Name) != 2 || len(account.Tags) != 2 || account.Limits == nil || account.Owner == nil {
+			t.Errorf("Expected sized values for every exported field, got %#v", account)
+		}
+		if account.Created.IsZero() {
+			t.Errorf("Expected arbitrary time, got zero")
+		}
+		if account.cache != "" {
+			t.Errorf("Expected unexported fields to be zero, got %q", account.cache)
+		}
+	}
+
+	owners := NewReturnsForMethod(t, accountsMethod("owners"), Arbitrary(Seed(1), SizeBetween(1, 3)))
+	returns, _ := owners.Receive()
+	if size := len(returns[0].(map[string]*testUser)); size < 1 || size > 3 {
+		t.Errorf("Expected between 1 and 3 owners, got %d", size)
+	}
+}
+
+func TestArbitrary_IsReproducible(t *testing.T) {
+	sample := func(seed int64) []testAccount {
+		rv := NewReturnsForMethod(t, accountsMethod("account"), Arbitrary(Seed(seed)))
+		first, _ := receiveAccount(t, rv)
+		second, _ := receiveAccount(t, rv)
+		return []testAccount{first, second}
+	}
+	if first, second := sample(7), sample(7); !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected same seed to give same values %v, got %v", first, second)
+	}
+	if first, second := sample(7), sample(8); reflect.DeepEqual(first, second) {
+		t.Errorf("Expected different seeds to give different values, got %v", first)
+	}
+}
+
+func TestArbitrary_Options(t *testing.T) {
+	rv := NewReturnsForMethod(t, accountsMethod("account"), Arbitrary(Seed(1),
+		Generator(func(r Random) string { return "fixed" }),
+		Generator(func(r Random) int { return int(r.Int63n(10)) })))
+	account, _ := receiveAccount(t, rv)
+	if account.Name != "fixed" || account.ID < 0 || account.ID >= 10 {
+		t.Errorf("Expected generated Name and ID, got %#v", account)
+	}
+	for _, tag := range account.Tags {
+		if tag != "fixed" {
+			t.Errorf("Expected generated Tags, got %v", account.Tags)
+		}
+	}
+
+	rv = NewReturnsForMethod(t, accountsMethod("account"), Arbitrary(Seed(1), ArbitraryErrors(1)))
+	if account, err := receiveAccount(t, rv); err == nil || !reflect.DeepEqual(account, testAccount{}) {
+		t.Errorf("Expected error with zero account, got %#v, %v", account, err)
+	}
+}
+
+func TestArbitrary_FatallyFailsTheTest(t *testing.T) {
+	type test struct {
+		name        string
+		rv          ReturnValues
+		expectedMsg string
+	}
+
+	tests := []test{
+		{"Generator", Arbitrary(Seed(1), Generator(func() int { return 1 })), `Generator expected func\(Random\) T, got func\(\) int`},
+		{"SizeBetween", Arbitrary(Seed(1), SizeBetween(3, 2)), `SizeBetween expected 0 <= min <= max, got 3,2`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			NewReturnsForMethod(tDouble, accountsMethod("account"), test.rv)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
+
+func TestCheckProperty(t *testing.T) {
+	var seeds []int64
+	CheckProperty(t, 5, 10, func(t T, seed int64) {
+		seeds = append(seeds, seed)
+	})
+	if expected := []int64{10, 11, 12, 13, 14}; !reflect.DeepEqual(seeds, expected) {
+		t.Errorf("Expected seeds %v, got %v", expected, seeds)
+	}
+}
+
+func TestCheckProperty_ReportsFailingSeed(t *testing.T) {
+	doubleT := NewTDouble(t)
+	spy := doubleT.Spy("Errorf")
+	doubleT.Stub("Logf")
+
+	var seeds []int64
+	CheckProperty(doubleT, 5, 1, func(t T, seed int64) {
+		seeds = append(seeds, seed)
+		if seed == 3 {
+			t.Errorf("failed for %d", seed)
+		}
+	})
+
+	if expected := []int64{1, 2, 3}; !reflect.DeepEqual(seeds, expected) {
+		t.Errorf("Expected to stop at the failing seed, ran %v", seeds)
+	}
+	spy.Matching(printfMatcher(`^seed 3: failed for 3$`)).Expect(Once())
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package clock provides a FakeClock whose time only moves when the test advances it.
+
+FakeClock.After is a godouble.Timewarp, so it plugs into the optional sleeper parameters. eg
+ clk := clock.NewFakeClock()
+ d := NewAPIDouble(t, clk.Configure)
+ d.Stub("Call").Returning(godouble.Delayed(godouble.Values(1), time.Second, clk.After))
+
+ go func() { result <- d.Call() }()
+ clk.BlockUntil(1)         //wait for the call to be sleeping
+ clk.Advance(time.Second)  //wake it up
+*/
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lwoggardner/godouble/godouble"
+)
+
+// sleeper is a pending After, Timer or Ticker
+type sleeper struct {
+	at     time.Time
+	period time.Duration //non zero for tickers
+	c      chan time.Time
+}
+
+// FakeClock is a manually advanced clock, safe for concurrent use
+type FakeClock struct {
+	mutex    *sync.Mutex
+	changed  *sync.Cond
+	now      time.Time
+	sleepers []*sleeper
+}
+
+// NewFakeClock returns a FakeClock starting at start, or at the current time if start is not provided
+func NewFakeClock(start ...time.Time) *FakeClock {
+	now := time.Now()
+	if len(start) > 0 {
+		now = start[0]
+	}
+	mutex := &sync.Mutex{}
+	return &FakeClock{mutex: mutex, changed: sync.NewCond(mutex), now: now}
+}
+
+// Now returns the current fake time
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Since returns the fake time elapsed since t
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// After waits for the clock to be advanced by d then sends the current fake time on the returned channel
+//
+// It is a godouble.Timewarp
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.add(d, 0).c
+}
+
+// Sleep blocks until the clock is advanced by d
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Advance moves the clock forward by d, firing each due timer and ticker in the order they are due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	until := c.now.Add(d)
+	for len(c.sleepers) > 0 && !c.sleepers[0].at.After(until) {
+		s := c.sleepers[0]
+		c.sleepers = c.sleepers[1:]
+		c.now = s.at
+		select {
+		case s.c <- s.at:
+		default:
+			//like time.Ticker, drop ticks for slow receivers
+		}
+		if s.period > 0 {
+			s.at = s.at.Add(s.period)
+			c.insert(s)
+		}
+	}
+	c.now = until
+	c.changed.Broadcast()
+}
+
+// BlockUntil blocks until at least n timers, tickers or calls to After or Sleep are waiting on the clock
+func (c *FakeClock) BlockUntil(n int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for len(c.sleepers) < n {
+		c.changed.Wait()
+	}
+}
+
+// Sleepers returns the number of timers, tickers and calls to After or Sleep waiting on the clock
+func (c *FakeClock) Sleepers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.sleepers)
+}
+
+// Configure is a godouble.TestDouble configurator that records call times with this clock
+func (c *FakeClock) Configure(d *godouble.TestDouble) {
+	d.SetClock(c.Now)
+}
+
+func (c *FakeClock) add(d time.Duration, period time.Duration) *sleeper {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	s := &sleeper{at: c.now.Add(d), period: period, c: make(chan time.Time, 1)}
+	if d <= 0 && period == 0 {
+		s.c <- c.now
+		return s
+	}
+	c.insert(s)
+	return s
+}
+
+// insert adds s in the order it is due, after any others due at the same time. Must hold mutex
+func (c *FakeClock) insert(s *sleeper) {
+	i := sort.Search(len(c.sleepers), func(i int) bool { return c.sleepers[i].at.After(s.at) })
+	c.sleepers = append(c.sleepers, nil)
+	copy(c.sleepers[i+1:], c.sleepers[i:])
+	c.sleepers[i] = s
+	c.changed.Broadcast()
+}
+
+// remove returns true if s was waiting and has been removed. Must hold mutex
+func (c *FakeClock) remove(s *sleeper) bool {
+	for i, waiting := range c.sleepers {
+		if waiting == s {
+			c.sleepers = append(c.sleepers[:i], c.sleepers[i+1:]...)
+			c.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// Timer is the FakeClock equivalent of time.Timer
+type Timer struct {
+	C     <-chan time.Time
+	clock *FakeClock
+	s     *sleeper
+}
+
+// NewTimer returns a Timer that sends the current fake time on its channel after the clock is advanced by d
+func (c *FakeClock) NewTimer(d time.Duration) *Timer {
+	s := c.add(d, 0)
+	return &Timer{C: s.c, clock: c, s: s}
+}
+
+// Stop prevents the Timer from firing, returning false if it had already fired or been stopped
+func (t *Timer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	return t.clock.remove(t.s)
+}
+
+// Reset changes the timer to fire after the clock is advanced by d, returning true if it had been active
+func (t *Timer) Reset(d time.Duration) bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	active := t.clock.remove(t.s)
+	t.s.at = t.clock.now.Add(d)
+	t.clock.insert(t.s)
+	return active
+}
+
+// Ticker is the FakeClock equivalent of time.Ticker
+type Ticker struct {
+	C     <-chan time.Time
+	clock *FakeClock
+	s     *sleeper
+}
+
+// NewTicker returns a Ticker that sends the current fake time on its channel each time the clock passes a multiple of d
+//
+// d must be greater than zero
+func (c *FakeClock) NewTicker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	s := c.add(d, d)
+	return &Ticker{C: s.c, clock: c, s: s}
+}
+
+// Stop turns off the ticker
+func (t *Ticker) Stop() {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	t.clock.remove(t.s)
+}
+
+// Reset stops the ticker and resets its period to d
+func (t *Ticker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	t.clock.remove(t.s)
+	t.s.at = t.clock.now.Add(d)
+	t.s.period = d
+	t.clock.insert(t.s)
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lwoggardner/godouble/godouble"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func expectFired(t *testing.T, c <-chan time.Time, at time.Time) {
+	t.Helper()
+	select {
+	case fired := <-c:
+		if !fired.Equal(at) {
+			t.Errorf("Expected to fire at %v, got %v", at, fired)
+		}
+	default:
+		t.Errorf("Expected to have fired at %v", at)
+	}
+}
+
+func expectNotFired(t *testing.T, c <-chan time.Time) {
+	t.Helper()
+	select {
+	case fired := <-c:
+		t.Errorf("Expected not to have fired, got %v", fired)
+	default:
+	}
+}
+
+func TestFakeClock_After(t *testing.T) {
+	clk := NewFakeClock(start)
+	later, sooner, now := clk.After(2*time.Second), clk.After(time.Second), clk.After(0)
+
+	expectFired(t, now, start)
+	expectNotFired(t, sooner)
+
+	clk.Advance(1500 * time.Millisecond)
+	expectFired(t, sooner, start.Add(time.Second))
+	expectNotFired(t, later)
+	if !clk.Now().Equal(start.Add(1500 * time.Millisecond)) {
+		t.Errorf("Expected now to be advanced, got %v", clk.Now())
+	}
+
+	clk.Advance(time.Second)
+	expectFired(t, later, start.Add(2*time.Second))
+	if clk.Sleepers() != 0 {
+		t.Errorf("Expected no sleepers, got %d", clk.Sleepers())
+	}
+	if since := clk.Since(start); since != 2500*time.Millisecond {
+		t.Errorf("Expected 2.5s since start, got %v", since)
+	}
+}
+
+func TestFakeClock_Timer(t *testing.T) {
+	clk := NewFakeClock(start)
+	timer := clk.NewTimer(time.Second)
+
+	if !timer.Stop() {
+		t.Errorf("Expected active timer to stop")
+	}
+	clk.Advance(time.Second)
+	expectNotFired(t, timer.C)
+
+	if timer.Reset(time.Second) {
+		t.Errorf("Expected stopped timer to have been inactive")
+	}
+	clk.Advance(time.Second)
+	expectFired(t, timer.C, start.Add(2*time.Second))
+	if timer.Stop() {
+		t.Errorf("Expected fired timer to not be active")
+	}
+}
+
+func TestFakeClock_Ticker(t *testing.T) {
+	clk := NewFakeClock(start)
+	ticker := clk.NewTicker(time.Second)
+
+	clk.Advance(time.Second)
+	expectFired(t, ticker.C, start.Add(time.Second))
+
+	clk.Advance(3 * time.Second)
+	expectFired(t, ticker.C, start.Add(2*time.Second)) //later ticks dropped
+	expectNotFired(t, ticker.C)
+
+	ticker.Reset(2 * time.Second)
+	clk.Advance(time.Second)
+	expectNotFired(t, ticker.C)
+	clk.Advance(time.Second)
+	expectFired(t, ticker.C, start.Add(6*time.Second))
+
+	ticker.Stop()
+	clk.Advance(10 * time.Second)
+	expectNotFired(t, ticker.C)
+}
+
+func TestFakeClock_BlockUntilSleeping(t *testing.T) {
+	clk := NewFakeClock(start)
+	woken := make(chan time.Time)
+	go func() {
+		clk.Sleep(time.Minute)
+		woken <- clk.Now()
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+	if at := <-woken; !at.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected to wake at %v, got %v", start.Add(time.Minute), at)
+	}
+}
+
+type api interface {
+	Call() int
+}
+
+type apiDouble struct {
+	api
+	*godouble.TestDouble
+}
+
+func (d *apiDouble) Call() int {
+	return d.Invoke("Call")[0].(int)
+}
+
+func TestFakeClock_DelaysReturnValues(t *testing.T) {
+	clk := NewFakeClock(start)
+	rv := godouble.Delayed(godouble.Values(1), time.Second, clk.After)
+
+	result := make(chan []interface{})
+	go func() {
+		returns, _ := rv.Receive()
+		result <- returns
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	if returns := <-result; returns[0] != 1 {
+		t.Errorf("Expected 1, got %v", returns)
+	}
+}
+
+func TestFakeClock_ConfiguresTestDouble(t *testing.T) {
+	clk := NewFakeClock(start)
+	d := &apiDouble{TestDouble: godouble.NewDouble(t, (*api)(nil), clk.Configure)}
+	spy := d.Spy("Call").Returning(godouble.Values(1))
+
+	d.Call()
+	clk.Advance(time.Minute)
+	d.Call()
+
+	spy.Expect(godouble.WithinDuration(godouble.Exactly(2), time.Minute))
+	spy.Expect(godouble.NoFasterThan(1, time.Minute))
+}
+
+func TestFakeClock_TimesOutReturnChannel(t *testing.T) {
+	clk := NewFakeClock(start)
+	rc := godouble.NewReturnChannel()
+	rc.SetTimeout(time.Second, clk.After)
+
+	errs := make(chan error)
+	go func() {
+		_, err := rc.Receive()
+		errs <- err
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	if err := <-errs; err == nil {
+		t.Errorf("Expected timeout error")
+	}
+}
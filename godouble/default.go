@@ -18,6 +18,7 @@ package godouble
 
 import (
 	"reflect"
+	"time"
 )
 
 func defaults(d *TestDouble) {
@@ -25,6 +26,7 @@ func defaults(d *TestDouble) {
 	d.SetReturnValuesIntegration(integrateReturnValues)
 	d.SetDefaultReturnValues(defaultReturnValues)
 	d.SetDefaultCall(defaultCall)
+	d.SetClock(time.Now)
 	d.EnableTrace()
 }
 func defaultCall(m Method) MethodCall {
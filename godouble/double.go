@@ -19,6 +19,8 @@ package godouble
 import (
 	"fmt"
 	"reflect"
+	"sync"
+	"time"
 )
 
 //T is compatible with builtin testing.T
@@ -81,6 +83,12 @@ type TestDouble struct {
 	trace               bool
 	matcher             MatcherForMethod
 	returns             ReturnsForMethod
+	transcript          *Transcript
+	clock               func() time.Time
+	started             time.Time
+	configurators       []func(*TestDouble)
+	childMutex          *sync.Mutex
+	children            map[string]*childDouble
 }
 
 // Enable tracing of all received method calls (via T.Logf)
@@ -105,12 +113,22 @@ func (d *TestDouble) SetDefaultCall(defaultCall func(Method) MethodCall) {
 /*
 	SetDefaultReturnValues allows a caller to provide a function to generate default return values
 	for a Stub, Mock, or Spy that was not explicitly registered with ReturnValues during Setup.
-	The default is to used zeroed values via reflection.
+	The default is to used zeroed values via reflection. See also SmartDefaults
 */
 func (d *TestDouble) SetDefaultReturnValues(defaultReturns func(Method) ReturnValues) {
 	d.defaultReturnValues = defaultReturns
 }
 
+/*
+	SetClock allows a caller to provide the source of the current time for recording when calls are made,
+	eg a fake clock's Now method. Times are relative to when the TestDouble was created.
+
+	The default is time.Now
+*/
+func (d *TestDouble) SetClock(now func() time.Time) {
+	d.clock = now
+}
+
 func (d *TestDouble) SetMatcherIntegration(forMethod MatcherForMethod) {
 	d.matcher = forMethod
 }
@@ -130,7 +148,9 @@ func (d *TestDouble) T() T {
 //MethodCall is an abstract interface of specific call types, Stub, Mock, Spy and Fake
 type MethodCall interface {
 	matches(args []interface{}) bool
-	spy(args []interface{}) ([]interface{}, error)
+	explain(args []interface{}) (bool, string)
+	//spy records a call and returns the values to receive, outside the method mutex, for its results
+	spy(args []interface{}) ReturnValues
 	verify(T)
 }
 
@@ -150,9 +170,12 @@ func NewDouble(t T, forInterface interface{}, configurators ...func(*TestDouble)
 	doubleFor = doubleFor.Elem()
 
 	double := &TestDouble{
-		t:            t,
-		forInterface: doubleFor,
-		methods:      make(map[string]*method, doubleFor.NumMethod()),
+		t:             t,
+		forInterface:  doubleFor,
+		methods:       make(map[string]*method, doubleFor.NumMethod()),
+		configurators: configurators,
+		childMutex:    &sync.Mutex{},
+		children:      map[string]*childDouble{},
 	}
 
 	for i := 0; i < doubleFor.NumMethod(); i++ {
@@ -177,6 +200,11 @@ func NewDouble(t T, forInterface interface{}, configurators ...func(*TestDouble)
 		t.Fatalf("%v needs SetDefaultCall configured", doubleFor)
 	}
 
+	if double.clock == nil {
+		t.Fatalf("%v needs SetClock configured", doubleFor)
+	}
+	double.started = double.clock()
+
 	return double
 }
 
@@ -215,7 +243,7 @@ Mock adds and returns a MockedMethodCall for methodName on TestDouble d
 
 Setup Phase
 
-Configure Matcher, sequencing (After), and Return Values.
+Configure Matcher, sequencing (After, InSequence), and Return Values.
 
 Set Expectation on number of matching invocations.
 
@@ -326,6 +354,12 @@ func (d *TestDouble) Verify() {
 			methodCall.verify(d.t)
 		}
 	}
+
+	d.childMutex.Lock()
+	defer d.childMutex.Unlock()
+	for _, child := range d.children {
+		child.double.Verify()
+	}
 }
 
 //Invoke is called by specialised mock implementations, and sometimes by Fake implementations
@@ -213,6 +213,37 @@ func TestTestDouble_VerifyErrorsForMocksWhoseExpectationsHaveNotBeenMet(t *testi
 	spy.Matching(printfMatcher("other")).Expect(Once())
 }
 
+func TestTestDouble_VerifyExplainsUnmatchedCalls(t *testing.T) {
+	doubleT := NewTDouble(t)
+	spy := doubleT.Spy("Errorf")
+
+	d1 := newApiDouble(doubleT, func(c *TestDouble) { c.DisableTrace() })
+	d1.Mock("call").Matching(HasPrefix("x")).Expect(Once())
+	d1.call("yy")
+
+	d1.Verify()
+
+	spy.Matching(printfMatcher(`(?s)call matching Args\(HasPrefix\("x"\)\) expected exactly 1, found 0 calls` +
+		`\n  tick \d+: \[yy\]\n    arg\[0\]: "yy" did not match HasPrefix\("x"\)$`)).Expect(Once())
+	spy.Matching(printfMatcher(`(?s)call expected never, found 1 calls` +
+		`\n  tick \d+: \[yy\] did not match:\n    .*call matching Args\(HasPrefix\("x"\)\)\n      arg\[0\]: "yy" did not match HasPrefix\("x"\)$`)).Expect(Once())
+}
+
+func TestInvoke_TracesMismatchedCalls(t *testing.T) {
+	doubleT := NewTDouble(t)
+	spy := doubleT.Spy("Logf")
+
+	d1 := newApiDouble(doubleT, func(c *TestDouble) { c.EnableTrace() })
+	d1.Stub("call").Matching("x").Returning(1)
+	d1.Mock("call").Matching(Len(2)).Expect(Once())
+	d1.call("yy")
+	d1.call("zz")
+
+	spy.Matching(printfMatcher(`(?s)call\(\[zz\]\) did not match:` +
+		`\n  .*call matching Args\(Eql\(x\)\)\n    arg\[0\]: "zz" did not match Eql\(x\)` +
+		`\n  .*call matching Args\(Len\(Eql\(2\)\)\)\n    already completed exactly 1 calls$`)).Expect(Once())
+}
+
 func assertMatch(t *testing.T, s interface{}, re string) {
 	t.Helper()
 	toMatch := fmt.Sprint(s)
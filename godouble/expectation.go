@@ -16,7 +16,10 @@
 
 package godouble
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // An Expectation verifies a count against an expected Value
 type Expectation interface {
@@ -31,6 +34,46 @@ type Completion interface {
 	Complete(count int) bool
 }
 
+// A TimedExpectation verifies the times that calls were made, as recorded by the TestDouble's clock (see SetClock)
+type TimedExpectation interface {
+	Expectation
+	// Is the expectation met for calls made at times 'at'? 'since' is when the TestDouble was created
+	MetAt(since time.Time, at []time.Time) bool
+}
+
+// A TimedCompletion is a TimedExpectation that can also be a Completion
+type TimedCompletion interface {
+	TimedExpectation
+	Complete(count int) bool
+}
+
+func expectationMet(expect Expectation, since time.Time, calls []*recordedCall) bool {
+	if timed, isTimed := expect.(TimedExpectation); isTimed {
+		return timed.MetAt(since, callTimes(calls))
+	}
+	return expect.Met(len(calls))
+}
+
+func callTimes(calls []*recordedCall) []time.Time {
+	times := make([]time.Time, len(calls))
+	for i, call := range calls {
+		times[i] = call.at
+	}
+	return times
+}
+
+// describeCalls describes the count of calls, and for a TimedExpectation the offsets since the double was created
+func describeCalls(expect Expectation, since time.Time, calls []*recordedCall) string {
+	if _, isTimed := expect.(TimedExpectation); isTimed {
+		offsets := make([]string, len(calls))
+		for i, call := range calls {
+			offsets[i] = "+" + call.at.Sub(since).String()
+		}
+		return fmt.Sprintf("%d calls at %v", len(calls), offsets)
+	}
+	return fmt.Sprintf("%d calls", len(calls))
+}
+
 type calledExactly int
 
 func (times calledExactly) Met(count int) bool {
@@ -122,3 +165,85 @@ func AtMost(n int) Completion {
 func Between(min int, max int) Completion {
 	return &calledBetween{min, max}
 }
+
+type calledWithin struct {
+	Expectation
+	within time.Duration
+}
+
+func (c calledWithin) MetAt(since time.Time, at []time.Time) bool {
+	if !c.Expectation.Met(len(at)) {
+		return false
+	}
+	for _, t := range at {
+		if t.Sub(since) > c.within {
+			return false
+		}
+	}
+	return true
+}
+
+func (c calledWithin) Complete(count int) bool {
+	if completion, isCompletion := c.Expectation.(Completion); isCompletion {
+		return completion.Complete(count)
+	}
+	return false
+}
+
+func (c calledWithin) String() string {
+	return fmt.Sprintf("%v within %v", c.Expectation, c.within)
+}
+
+// WithinDuration returns an expectation that expect is met by calls that were all made within d of
+// the TestDouble being created.
+//
+// The expectation is considered complete when expect is complete
+func WithinDuration(expect Expectation, d time.Duration) TimedCompletion {
+	return calledWithin{expect, d}
+}
+
+type calledNoFasterThan struct {
+	n   int
+	per time.Duration
+}
+
+func (c calledNoFasterThan) Met(_ int) bool {
+	return true
+}
+
+func (c calledNoFasterThan) MetAt(_ time.Time, at []time.Time) bool {
+	for i := 0; i+c.n < len(at); i++ {
+		if at[i+c.n].Sub(at[i]) < c.per {
+			return false
+		}
+	}
+	return true
+}
+
+func (c calledNoFasterThan) String() string {
+	return fmt.Sprintf("no faster than %d per %v", c.n, c.per)
+}
+
+// NoFasterThan returns an expectation that no more than n calls are made in any period of duration per
+func NoFasterThan(n int, per time.Duration) TimedExpectation {
+	return calledNoFasterThan{n, per}
+}
+
+type calledSpacedAtLeast time.Duration
+
+func (c calledSpacedAtLeast) Met(_ int) bool {
+	return true
+}
+
+func (c calledSpacedAtLeast) MetAt(_ time.Time, at []time.Time) bool {
+	return NoFasterThan(1, time.Duration(c)).MetAt(time.Time{}, at)
+}
+
+func (c calledSpacedAtLeast) String() string {
+	return fmt.Sprintf("spaced at least %v", time.Duration(c))
+}
+
+// SpacedAtLeast returns an expectation that successive calls are at least d apart
+func SpacedAtLeast(d time.Duration) TimedExpectation {
+	return calledSpacedAtLeast(d)
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 )
 
 func TestExpectations(t *testing.T) {
@@ -78,3 +79,84 @@ func TestExpectations(t *testing.T) {
 		})
 	}
 }
+
+func TestTimedExpectations(t *testing.T) {
+	type test struct {
+		name string
+		TimedExpectation
+		truthy [][]int
+		falsey [][]int
+		re     string
+	}
+	ms := time.Millisecond
+
+	tests := []test{
+		{"WithinDuration", WithinDuration(Twice(), 100*ms), [][]int{{0, 100}, {50, 60}}, [][]int{{10}, {0, 101}, {1, 2, 3}}, "exactly 2 within 100ms"},
+		{"WithinDurationAtLeast", WithinDuration(AtLeast(1), time.Second), [][]int{{0}, {999, 1000}}, [][]int{{}, {1001}}, "at least 1 within 1s"},
+		{"NoFasterThan", NoFasterThan(2, 100*ms), [][]int{{}, {0}, {0, 1, 100, 101, 200}, {0, 10, 150, 160}}, [][]int{{0, 1, 2}, {0, 50, 99}, {0, 100, 110, 150}}, "no faster than 2 per 100ms"},
+		{"SpacedAtLeast", SpacedAtLeast(100 * ms), [][]int{{}, {5}, {0, 100, 300}}, [][]int{{0, 99}, {0, 100, 150}}, "spaced at least 100ms"},
+	}
+
+	since := time.Date(2020, 2, 15, 20, 35, 3, 0, time.UTC)
+	at := func(offsets []int) []time.Time {
+		times := make([]time.Time, len(offsets))
+		for i, offset := range offsets {
+			times[i] = since.Add(time.Duration(offset) * ms)
+		}
+		return times
+	}
+
+	for _, tt := range tests {
+		ex := tt
+		t.Run(ex.name, func(t *testing.T) {
+			if !regexp.MustCompile(ex.re).MatchString(fmt.Sprint(ex.TimedExpectation)) {
+				t.Errorf("Expected %v to match /%s/", ex.TimedExpectation, ex.re)
+			}
+			for _, expectTrue := range ex.truthy {
+				if !ex.MetAt(since, at(expectTrue)) {
+					t.Errorf("expected %v to be met for calls at %v, but is not", ex.TimedExpectation, expectTrue)
+				}
+			}
+			for _, expectFalse := range ex.falsey {
+				if ex.MetAt(since, at(expectFalse)) {
+					t.Errorf("expected %v to not be met for calls at %v, but is", ex.TimedExpectation, expectFalse)
+				}
+			}
+		})
+	}
+}
+
+func TestTimedExpectations_UseTheDoubleClock(t *testing.T) {
+	doubleT := NewTDouble(t, func(c *TestDouble) {
+		//c.EnableTrace()
+	})
+	spy := doubleT.Spy("Errorf")
+
+	now := time.Date(2020, 2, 15, 20, 35, 3, 0, time.UTC)
+	d1 := newApiDouble(doubleT, func(c *TestDouble) {
+		c.DisableTrace()
+		c.SetClock(func() time.Time { return now })
+	})
+	calls := d1.Spy("call")
+	mock := d1.Mock("other").Expect(WithinDuration(Once(), time.Second))
+
+	for _, s := range []string{"one", "two", "three"} {
+		now = now.Add(100 * time.Millisecond)
+		d1.call(s)
+	}
+	now = now.Add(time.Second)
+	d1.other()
+	if !mock.complete() {
+		t.Errorf("Expected %v to be complete", mock)
+	}
+
+	calls.Expect(SpacedAtLeast(100 * time.Millisecond))
+	calls.Expect(WithinDuration(Exactly(3), 300*time.Millisecond))
+	spy.Expect(Never())
+
+	calls.Expect(NoFasterThan(2, 250*time.Millisecond))
+	spy.Matching(printfMatcher(`call expected no faster than 2 per 250ms, found 3 calls at \[\+100ms \+200ms \+300ms\]`)).Expect(Once())
+
+	d1.Verify()
+	spy.Matching(printfMatcher(`other expected exactly 1 within 1s, found 1 calls at \[\+1.3s\]`)).Expect(Once())
+}
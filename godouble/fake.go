@@ -42,9 +42,9 @@ func newFakeMethodCall(m *method, impl interface{}) *fakeMethodCall {
 	return &fakeMethodCall{spyMethodCall: newSpyMethodCall(m), impl: implF}
 }
 
-func (c *fakeMethodCall) spy(args []interface{}) ([]interface{}, error) {
+func (c *fakeMethodCall) spy(args []interface{}) ReturnValues {
 	//Record the call first, in case the actual call panics.
-	c.recorded = append(c.recorded, newRecordedCall(args))
+	c.recorded = append(c.recorded, c.newRecordedCall(args))
 
 	inArgs := make([]reflect.Value, len(args))
 	for i, arg := range args {
@@ -58,11 +58,11 @@ func (c *fakeMethodCall) spy(args []interface{}) ([]interface{}, error) {
 	}
 
 	if len(returnVals) == 0 {
-		return nil, nil
+		return Values()
 	}
 	returns := make([]interface{}, len(returnVals))
 	for j, v := range returnVals {
 		returns[j] = v.Interface()
 	}
-	return returns, nil
+	return Values(returns...)
 }
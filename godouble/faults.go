@@ -0,0 +1,152 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Random is a source of random numbers, satisfied by *rand.Rand
+//
+// Pass an explicitly seeded Random so that a test run is reproducible.
+type Random interface {
+	Float64() float64
+	Int63n(n int64) int64
+}
+
+// Seed returns a Random seeded with seed
+func Seed(seed int64) Random {
+	return rand.New(rand.NewSource(seed))
+}
+
+// FaultyReturnValues are ReturnValues that inject failures, recording which invocations failed
+type FaultyReturnValues interface {
+	ReturnValues
+
+	//Outcomes returns whether each invocation so far failed, in order. See Replay
+	Outcomes() []bool
+}
+
+type faultyReturnValues struct {
+	mutex    *sync.Mutex
+	desc     string
+	fail     func(call int) bool //call counts from 1
+	errRV    ReturnValues
+	okRV     ReturnValues
+	outcomes []bool
+}
+
+func newFaultyReturnValues(desc string, fail func(call int) bool, errRV ReturnValues, okRV ReturnValues) FaultyReturnValues {
+	return &faultyReturnValues{mutex: &sync.Mutex{}, desc: desc, fail: fail, errRV: errRV, okRV: okRV}
+}
+
+func (f *faultyReturnValues) String() string {
+	return fmt.Sprintf("%s outcomes %v", f.desc, f.Outcomes())
+}
+
+func (f *faultyReturnValues) Receive() ([]interface{}, error) {
+	return f.ReceiveArgs(nil)
+}
+
+func (f *faultyReturnValues) ReceiveArgs(args []interface{}) ([]interface{}, error) {
+	f.mutex.Lock()
+	failed := f.fail(len(f.outcomes) + 1)
+	f.outcomes = append(f.outcomes, failed)
+	f.mutex.Unlock()
+	if failed {
+		return receiveArgs(f.errRV, args)
+	}
+	return receiveArgs(f.okRV, args)
+}
+
+func (f *faultyReturnValues) parks() bool { return parks(f.errRV) || parks(f.okRV) }
+
+func (f *faultyReturnValues) Verify(t T) {
+	t.Helper()
+	verifyAll(t, f.errRV, f.okRV)
+}
+
+func (f *faultyReturnValues) Outcomes() []bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]bool(nil), f.outcomes...)
+}
+
+func (f *faultyReturnValues) ForMethod(t T, m reflect.Method) {
+	t.Helper()
+	for _, rv := range []ReturnValues{f.errRV, f.okRV} {
+		if validatingRV, isValidating := rv.(ValidatingReturnValues); isValidating {
+			validatingRV.ForMethod(t, m)
+		}
+	}
+}
+
+// FailRate returns values from errRV with probability p, otherwise from okRV
+//
+// eg FailRate(0.2, Values(0, errors.New("unavailable")), Values(1, nil), Seed(42))
+func FailRate(p float64, errRV ReturnValues, okRV ReturnValues, random Random) FaultyReturnValues {
+	return newFaultyReturnValues(fmt.Sprintf("FailRate(%v)", p), func(_ int) bool {
+		return random.Float64() < p
+	}, errRV, okRV)
+}
+
+// FailNth returns values from errRV for only the nth invocation (counting from 1), otherwise from okRV
+func FailNth(n int, errRV ReturnValues, okRV ReturnValues) FaultyReturnValues {
+	return newFaultyReturnValues(fmt.Sprintf("FailNth(%d)", n), func(call int) bool {
+		return call == n
+	}, errRV, okRV)
+}
+
+// FailEvery returns values from errRV for every kth invocation, otherwise from okRV
+func FailEvery(k int, errRV ReturnValues, okRV ReturnValues) FaultyReturnValues {
+	return newFaultyReturnValues(fmt.Sprintf("FailEvery(%d)", k), func(call int) bool {
+		return k > 0 && call%k == 0
+	}, errRV, okRV)
+}
+
+/*
+FailFor returns values from errRV for invocations within d of the first invocation, simulating an outage,
+and thereafter from okRV.
+
+An optional clock, defaulting to time.Now, can be provided. eg for use with fake clock
+*/
+func FailFor(d time.Duration, errRV ReturnValues, okRV ReturnValues, clock ...func() time.Time) FaultyReturnValues {
+	now := time.Now
+	if len(clock) > 0 {
+		now = clock[0]
+	}
+	var started time.Time
+	return newFaultyReturnValues(fmt.Sprintf("FailFor(%v)", d), func(call int) bool {
+		if call == 1 {
+			started = now()
+		}
+		return now().Sub(started) < d
+	}, errRV, okRV)
+}
+
+// Replay returns values from errRV or okRV as per outcomes recorded by a previous run, and thereafter from okRV
+//
+// eg to reproduce a failing run, log rv.Outcomes() and replace rv with Replay(outcomes, errRV, okRV)
+func Replay(outcomes []bool, errRV ReturnValues, okRV ReturnValues) FaultyReturnValues {
+	return newFaultyReturnValues(fmt.Sprintf("Replay(%v)", outcomes), func(call int) bool {
+		return call <= len(outcomes) && outcomes[call-1]
+	}, errRV, okRV)
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func receiveOutcomes(t *testing.T, rv ReturnValues, calls int) []bool {
+	t.Helper()
+	failures := make([]bool, calls)
+	for i := range failures {
+		returns, err := rv.Receive()
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		failures[i] = returns[1] != nil
+	}
+	return failures
+}
+
+func TestFaultyReturnValues(t *testing.T) {
+	errRV, okRV := Values(0, errors.New("unavailable")), Values(1, nil)
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	type test struct {
+		name     string
+		values   FaultyReturnValues
+		expected []bool
+	}
+
+	tests := []test{
+		{"FailNth", FailNth(2, errRV, okRV), []bool{false, true, false, false}},
+		{"FailEvery", FailEvery(2, errRV, okRV), []bool{false, true, false, true, false}},
+		{"FailEveryZero", FailEvery(0, errRV, okRV), []bool{false, false}},
+		{"FailRateNever", FailRate(0, errRV, okRV, Seed(1)), []bool{false, false, false}},
+		{"FailRateAlways", FailRate(1, errRV, okRV, Seed(1)), []bool{true, true, true}},
+		{"FailFor", FailFor(time.Second, errRV, okRV, clock), []bool{true, true}},
+		{"Replay", Replay([]bool{false, true, true}, errRV, okRV), []bool{false, true, true, false, false}},
+	}
+
+	testMethod, _ := reflect.TypeOf((*api)(nil)).Elem().MethodByName("test")
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			NewReturnsForMethod(t, testMethod, test.values)
+			if outcomes := receiveOutcomes(t, test.values, len(test.expected)); !reflect.DeepEqual(outcomes, test.expected) {
+				t.Errorf("Expected %v, got %v", test.expected, outcomes)
+			}
+			if outcomes := test.values.Outcomes(); !reflect.DeepEqual(outcomes, test.expected) {
+				t.Errorf("Expected recorded outcomes %v, got %v", test.expected, outcomes)
+			}
+		})
+	}
+}
+
+func TestFailFor_RecoversAfterDuration(t *testing.T) {
+	now := time.Now()
+	rv := FailFor(time.Second, Values(0, errors.New("unavailable")), Values(1, nil), func() time.Time { return now })
+
+	outcomes := receiveOutcomes(t, rv, 2)
+	now = now.Add(time.Second)
+	outcomes = append(outcomes, receiveOutcomes(t, rv, 1)...)
+
+	if expected := []bool{true, true, false}; !reflect.DeepEqual(outcomes, expected) {
+		t.Errorf("Expected %v, got %v", expected, outcomes)
+	}
+}
+
+func TestFailRate_IsReproducibleAndReplayable(t *testing.T) {
+	errRV, okRV := Values(0, errors.New("unavailable")), Values(1, nil)
+
+	first := FailRate(0.5, errRV, okRV, Seed(42))
+	outcomes := receiveOutcomes(t, first, 20)
+	second := receiveOutcomes(t, FailRate(0.5, errRV, okRV, Seed(42)), 20)
+	if !reflect.DeepEqual(outcomes, second) {
+		t.Errorf("Expected same seed to give same outcomes %v, got %v", outcomes, second)
+	}
+
+	replayed := receiveOutcomes(t, Replay(first.Outcomes(), errRV, okRV), 20)
+	if !reflect.DeepEqual(outcomes, replayed) {
+		t.Errorf("Expected replay to give outcomes %v, got %v", outcomes, replayed)
+	}
+}
+
+func TestFaultyReturnValues_ValidatesForMethod(t *testing.T) {
+	tDouble := NewTDouble(t)
+	spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+	defer func(spy FakeMethodCall) {
+		recover()
+		spy.Expect(Once())
+	}(spy)
+
+	testMethod, _ := reflect.TypeOf((*api)(nil)).Elem().MethodByName("test")
+	NewReturnsForMethod(tDouble, testMethod, FailNth(1, Values("not an int", nil), Values(1, nil)))
+	t.Errorf("Expect unreachable")
+}
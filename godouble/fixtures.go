@@ -0,0 +1,196 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// A FixtureDecoder decodes the data of a fixture file into v, eg json.Unmarshal
+type FixtureDecoder func(data []byte, v interface{}) error
+
+var fixtureDecoders = struct {
+	mutex    *sync.Mutex
+	decoders map[string]FixtureDecoder
+}{&sync.Mutex{}, map[string]FixtureDecoder{".json": json.Unmarshal}}
+
+/*
+RegisterFixtureDecoder registers decode for fixture files with extension ext. JSON (.json) is registered by default.
+
+eg to load YAML fixtures
+ RegisterFixtureDecoder(".yaml", yaml.Unmarshal)
+*/
+func RegisterFixtureDecoder(ext string, decode FixtureDecoder) {
+	fixtureDecoders.mutex.Lock()
+	defer fixtureDecoders.mutex.Unlock()
+	fixtureDecoders.decoders[strings.ToLower(ext)] = decode
+}
+
+func fixtureDecoder(path string) (decode FixtureDecoder, found bool) {
+	fixtureDecoders.mutex.Lock()
+	defer fixtureDecoders.mutex.Unlock()
+	decode, found = fixtureDecoders.decoders[strings.ToLower(filepath.Ext(path))]
+	return
+}
+
+type fixtureReturnValues struct {
+	mutex    *sync.Mutex
+	desc     string
+	paths    func() ([]string, error)
+	forever  bool //the last fixture is returned for all subsequent calls
+	fixtures []fixture
+	next     int
+}
+
+// fixture is the data of a fixture file, decoded afresh for each invocation so callers do not share values
+type fixture struct {
+	path       string
+	data       []byte
+	decode     FixtureDecoder
+	methodType reflect.Type
+	result     int //index of the single non error result, or -1
+}
+
+/*
+FromFixture returns values decoded from the file at path, for every invocation.
+
+The method's single non error result is decoded from the file with the decoder registered for its extension
+(see RegisterFixtureDecoder). A file containing only an error, eg {"error": "not found"}, instead returns that
+error with zero values for the other results.
+
+Fixtures are loaded when the values are provided to a method, failing the test fatally if they cannot be decoded.
+*/
+func FromFixture(path string) ReturnValues {
+	return &fixtureReturnValues{
+		mutex:   &sync.Mutex{},
+		desc:    fmt.Sprintf("FromFixture(%s)", path),
+		paths:   func() ([]string, error) { return []string{path}, nil },
+		forever: true,
+	}
+}
+
+// FromFixtures returns values decoded from each file matching the glob pattern, in lexical order, once each.
+// See FromFixture
+func FromFixtures(pattern string) ReturnValues {
+	return &fixtureReturnValues{
+		mutex: &sync.Mutex{},
+		desc:  fmt.Sprintf("FromFixtures(%s)", pattern),
+		paths: func() ([]string, error) {
+			paths, err := filepath.Glob(pattern)
+			if err == nil && len(paths) == 0 {
+				err = errors.New("no files match")
+			}
+			return paths, err
+		},
+	}
+}
+
+func (f *fixtureReturnValues) String() string {
+	return f.desc
+}
+
+func (f *fixtureReturnValues) multiValued() bool { return !f.forever }
+
+func (f *fixtureReturnValues) ForMethod(t T, m reflect.Method) {
+	t.Helper()
+	result := -1 //the single non error result
+	for i := 0; i < m.Type.NumOut(); i++ {
+		if i == m.Type.NumOut()-1 && m.Type.Out(i) == errorType {
+			continue
+		}
+		if result >= 0 {
+			t.Fatalf("%v for %v expects at most one result other than a final error", f, m.Type)
+		}
+		result = i
+	}
+
+	paths, err := f.paths()
+	if err != nil {
+		t.Fatalf("%v: %v", f, err)
+	}
+
+	fixtures := make([]fixture, len(paths))
+	for i, path := range paths {
+		decode, found := fixtureDecoder(path)
+		if !found {
+			t.Fatalf("%v: no decoder registered for %s, see RegisterFixtureDecoder", f, path)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%v: %v", f, err)
+		}
+		fixtures[i] = fixture{path: path, data: data, decode: decode, methodType: m.Type, result: result}
+		if _, err := fixtures[i].values(); err != nil {
+			t.Fatalf("%v for %v: %v", f, m.Type, err)
+		}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.fixtures = fixtures
+}
+
+// values decodes the fixture into the results of its method
+func (fx fixture) values() ([]interface{}, error) {
+	values := make([]interface{}, fx.methodType.NumOut())
+	for i := range values {
+		values[i] = reflect.Zero(fx.methodType.Out(i)).Interface()
+	}
+
+	last := len(values) - 1
+	if last >= 0 && fx.methodType.Out(last) == errorType {
+		var errorFixture map[string]interface{}
+		if fx.decode(fx.data, &errorFixture) == nil && len(errorFixture) == 1 {
+			if msg, isError := errorFixture["error"].(string); isError {
+				values[last] = errors.New(msg)
+				return values, nil
+			}
+		}
+	}
+
+	if fx.result >= 0 {
+		v := reflect.New(fx.methodType.Out(fx.result))
+		if err := fx.decode(fx.data, v.Interface()); err != nil {
+			return nil, fmt.Errorf("decoding %s: %v", fx.path, err)
+		}
+		values[fx.result] = v.Elem().Interface()
+	}
+	return values, nil
+}
+
+func (f *fixtureReturnValues) Receive() ([]interface{}, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.fixtures == nil {
+		return nil, fmt.Errorf("%v has not been loaded for a method", f)
+	}
+	if f.next >= len(f.fixtures) {
+		if !f.forever {
+			return nil, fmt.Errorf("no more fixtures after %d from %v", len(f.fixtures), f)
+		}
+		return f.fixtures[len(f.fixtures)-1].values()
+	}
+	f.next++
+	return f.fixtures[f.next-1].values()
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type users interface {
+	user(id int) (testUser, error)
+	current() *testUser
+	pair() (int, string)
+}
+
+func usersMethod(name string) reflect.Method {
+	m, _ := reflect.TypeOf((*users)(nil)).Elem().MethodByName(name)
+	return m
+}
+
+func receiveAll(t *testing.T, rv ReturnValues, n int) [][]interface{} {
+	t.Helper()
+	results := make([][]interface{}, n)
+	for i := range results {
+		returns, err := rv.Receive()
+		if err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+		results[i] = returns
+	}
+	return results
+}
+
+func TestFromFixture(t *testing.T) {
+	rv := NewReturnsForMethod(t, usersMethod("user"), FromFixture("testdata/user.json"))
+	fred := []interface{}{testUser{ID: 1, Name: "fred"}, nil}
+	if results := receiveAll(t, rv, 2); !reflect.DeepEqual(results, [][]interface{}{fred, fred}) {
+		t.Errorf("Expected fred for every call, got %v", results)
+	}
+
+	rv = NewReturnsForMethod(t, usersMethod("current"), FromFixture("testdata/user.json"))
+	results := receiveAll(t, rv, 2)
+	first, second := results[0][0].(*testUser), results[1][0].(*testUser)
+	if first.Name != "fred" || first == second {
+		t.Errorf("Expected a new fred for each call, got %v, %v", first, second)
+	}
+}
+
+func TestFromFixtures(t *testing.T) {
+	rv := NewReturnsForMethod(t, usersMethod("user"), FromFixtures("testdata/users/*.json"))
+	results := receiveAll(t, rv, 3)
+	if expected := []interface{}{testUser{ID: 1, Name: "fred"}, nil}; !reflect.DeepEqual(results[0], expected) {
+		t.Errorf("Expected %v, got %v", expected, results[0])
+	}
+	if user, err := results[1][0], results[1][1].(error); user != (testUser{}) || err.Error() != "user 2 not found" {
+		t.Errorf("Expected error fixture, got %v, %v", user, err)
+	}
+	if expected := []interface{}{testUser{ID: 3, Name: "wilma"}, nil}; !reflect.DeepEqual(results[2], expected) {
+		t.Errorf("Expected %v, got %v", expected, results[2])
+	}
+	if _, err := rv.Receive(); err == nil {
+		t.Errorf("Expected error after fixtures are exhausted")
+	}
+}
+
+func TestFromFixture_RegisteredDecoder(t *testing.T) {
+	RegisterFixtureDecoder(".csv", func(data []byte, v interface{}) error {
+		user, isUser := v.(*testUser)
+		if !isUser {
+			return fmt.Errorf("cannot decode csv into %T", v)
+		}
+		_, err := fmt.Sscanf(string(data), "%d,%s", &user.ID, &user.Name)
+		return err
+	})
+
+	rv := NewReturnsForMethod(t, usersMethod("user"), FromFixture("testdata/user.csv"))
+	if results := receiveAll(t, rv, 1); !reflect.DeepEqual(results[0], []interface{}{testUser{ID: 5, Name: "barney"}, nil}) {
+		t.Errorf("Expected barney, got %v", results[0])
+	}
+}
+
+func TestFromFixture_FatallyFailsTheTest(t *testing.T) {
+	type test struct {
+		name        string
+		method      string
+		rv          ReturnValues
+		expectedMsg string
+	}
+
+	tests := []test{
+		{"Missing", "user", FromFixture("testdata/missing.json"), `FromFixture\(testdata/missing.json\): .*missing.json`},
+		{"NoMatches", "user", FromFixtures("testdata/missing/*.json"), `FromFixtures\(testdata/missing/\*.json\): no files match`},
+		{"NoDecoder", "user", FromFixture("testdata/user.txt"), `no decoder registered for testdata/user.txt`},
+		{"Invalid", "user", FromFixture("testdata/invalid.json"), `decoding testdata/invalid.json`},
+		{"MultipleResults", "pair", FromFixture("testdata/user.json"), `expects at most one result other than a final error`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			NewReturnsForMethod(tDouble, usersMethod(test.method), test.rv)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
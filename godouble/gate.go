@@ -0,0 +1,187 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+/*
+Gate is ReturnValues that park each invocation until the test releases it, so the interleaving of concurrent callers
+can be controlled deterministically.
+
+Each invocation is reported as a GateCall on Arrivals, then waits for the GateCall (or the Gate) to be released. eg
+ gate := NewGate()
+ d.Stub("Fetch").Returning(gate)
+ go d.Fetch() // first
+ go d.Fetch() // second
+ calls := gate.AwaitArrivals(2)
+ calls[1].Release(2, nil) // second caller returns first
+ calls[0].Fail(errors.New("unavailable"))
+*/
+type Gate interface {
+
+	//Arrivals reports each invocation as it arrives at the gate. The invocation is not parked until it is received.
+	Arrivals() <-chan *GateCall
+
+	//AwaitArrivals waits for the next n invocations to arrive, failing the test fatally if they do not arrive before
+	//the timeout.
+	AwaitArrivals(n int) []*GateCall
+
+	//Release the earliest arrived invocation that has not been released, with returnValues
+	//
+	//Waits for an invocation to arrive if necessary
+	Release(returnValues ...interface{})
+
+	//Fail the earliest arrived invocation that has not been released, returning err and zero values
+	//
+	//Waits for an invocation to arrive if necessary
+	Fail(err error)
+
+	//Set a timeout. If an invocation is not received from Arrivals, or is not released, before the timeout expires
+	//the test will fail fatally
+	SetTimeout(timeout time.Duration, sleeper ...Timewarp)
+
+	ReturnValues
+}
+
+// A GateCall is an invocation that arrived at a Gate
+type GateCall struct {
+	//Arrival counts the invocations at the gate from 1
+	Arrival int
+
+	gate *gate
+	*parkedCall
+}
+
+func (c *GateCall) String() string {
+	return fmt.Sprintf("GateCall(%d)", c.Arrival)
+}
+
+// Release the invocation with returnValues
+func (c *GateCall) Release(returnValues ...interface{}) {
+	if c.gate.t != nil {
+		c.gate.t.Helper()
+		AssertMethodReturnValues(c.gate.t, c.gate.method, returnValues)
+	}
+	c.gate.answer(c, c.parkedCall, returnValues)
+}
+
+// Fail the invocation, returning err as its final error result and zero values for its other results
+func (c *GateCall) Fail(err error) {
+	g := c.gate
+	if g.t == nil {
+		panic(fmt.Sprintf("cannot fail %v, gate is not configured for a method", c))
+	}
+	g.t.Helper()
+	out := g.method.Type.NumOut()
+	if out == 0 || g.method.Type.Out(out-1) != errorType {
+		g.t.Fatalf("cannot fail %v, method %v does not return an error", c, g.method.Type)
+	}
+	returns := make([]interface{}, out)
+	for i := range returns[:out-1] {
+		returns[i] = reflect.Zero(g.method.Type.Out(i)).Interface()
+	}
+	returns[out-1] = err
+	g.answer(c, c.parkedCall, returns)
+}
+
+// NewGate creates a Gate
+//
+// Use SetTimeout() to override the default timeout of 200 ms.
+func NewGate() Gate {
+	arrivals := make(chan *GateCall)
+	return &gate{parking: newParking(arrivals, "arrivals", "released"), arrivals: arrivals}
+}
+
+type gate struct {
+	parking
+	arrivals chan *GateCall
+	parked   []*GateCall //arrived via AwaitArrivals, not yet released
+}
+
+func (g *gate) Receive() ([]interface{}, error) {
+	return g.park(func(number int, state *parkedCall) fmt.Stringer {
+		return &GateCall{Arrival: number, gate: g, parkedCall: state}
+	})
+}
+
+func (g *gate) Arrivals() <-chan *GateCall {
+	return g.arrivals
+}
+
+func (g *gate) AwaitArrivals(n int) []*GateCall {
+	if g.t != nil {
+		g.t.Helper()
+	}
+	calls := g.awaitArrivals(n)
+	g.mutex.Lock()
+	g.parked = append(g.parked, calls...)
+	g.mutex.Unlock()
+	return calls
+}
+
+func (g *gate) awaitArrivals(n int) []*GateCall {
+	if g.t != nil {
+		g.t.Helper()
+	}
+	arrived := g.await(n)
+	calls := make([]*GateCall, len(arrived))
+	for i, call := range arrived {
+		calls[i] = call.Interface().(*GateCall)
+	}
+	return calls
+}
+
+func (g *gate) Release(returnValues ...interface{}) {
+	if g.t != nil {
+		g.t.Helper()
+	}
+	if call := g.next(); call != nil {
+		call.Release(returnValues...)
+	}
+}
+
+func (g *gate) Fail(err error) {
+	if g.t != nil {
+		g.t.Helper()
+	}
+	if call := g.next(); call != nil {
+		call.Fail(err)
+	}
+}
+
+// next returns the earliest parked call that has not been released, waiting for one to arrive if necessary
+func (g *gate) next() *GateCall {
+	g.mutex.Lock()
+	for len(g.parked) > 0 {
+		call := g.parked[0]
+		g.parked = g.parked[1:]
+		if !call.answered {
+			g.mutex.Unlock()
+			return call
+		}
+	}
+	g.mutex.Unlock()
+
+	if calls := g.awaitArrivals(1); len(calls) > 0 {
+		return calls[0]
+	}
+	return nil
+}
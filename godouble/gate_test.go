@@ -0,0 +1,201 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type gateResult struct {
+	r int
+	e error
+}
+
+func callThroughGate(d *apiDouble, results chan gateResult) {
+	r, e := d.test(1, "gate")
+	results <- gateResult{r, e}
+}
+
+func TestGate_ReleasesCallersInChosenOrder(t *testing.T) {
+	d := newApiDouble(t)
+	gate := NewGate()
+	d.Stub("test").Returning(gate)
+
+	results := make(chan gateResult)
+	go callThroughGate(d, results)
+	go callThroughGate(d, results)
+
+	calls := gate.AwaitArrivals(2)
+	if calls[0].Arrival != 1 || calls[1].Arrival != 2 {
+		t.Errorf("Expected arrivals 1 and 2, got %v", calls)
+	}
+
+	unavailable := errors.New("unavailable")
+	calls[1].Release(2, nil)
+	if result := <-results; result != (gateResult{2, nil}) {
+		t.Errorf("Expected second caller to be released first, got %v", result)
+	}
+	calls[0].Fail(unavailable)
+	if result := <-results; result != (gateResult{0, unavailable}) {
+		t.Errorf("Expected first caller to fail, got %v", result)
+	}
+}
+
+func TestGate_ReleasesEarliestArrival(t *testing.T) {
+	d := newApiDouble(t)
+	gate := NewGate()
+	d.Stub("test").Returning(gate)
+
+	results := make(chan gateResult)
+	go callThroughGate(d, results)
+	gate.Release(1, nil) //waits for the arrival
+	if result := <-results; result != (gateResult{1, nil}) {
+		t.Errorf("Expected released values, got %v", result)
+	}
+
+	go callThroughGate(d, results)
+	go callThroughGate(d, results)
+	gate.AwaitArrivals(2)
+	gate.Fail(errors.New("first"))
+	if result := <-results; result.r != 0 || result.e == nil {
+		t.Errorf("Expected failure, got %v", result)
+	}
+	gate.Release(2, nil)
+	if result := <-results; result != (gateResult{2, nil}) {
+		t.Errorf("Expected released values, got %v", result)
+	}
+}
+
+func TestGate_Arrivals(t *testing.T) {
+	d := newApiDouble(t)
+	gate := NewGate()
+	d.Stub("test").Returning(gate)
+
+	results := make(chan gateResult)
+	go callThroughGate(d, results)
+	(<-gate.Arrivals()).Release(3, nil)
+	if result := <-results; result != (gateResult{3, nil}) {
+		t.Errorf("Expected released values, got %v", result)
+	}
+}
+
+func TestGate_InSequence(t *testing.T) {
+	tests := []struct {
+		name     string
+		sequence func(gate Gate) ReturnValues
+	}{
+		{"Sequence", func(gate Gate) ReturnValues { return Sequence(gate) }},
+		{"Returning", func(gate Gate) ReturnValues { return Returning(gate).ThenForever(2, nil) }},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			d := newApiDouble(t)
+			gate := NewGate()
+			d.Stub("test").Returning(test.sequence(gate))
+
+			results := make(chan gateResult)
+			go callThroughGate(d, results)
+			go callThroughGate(d, results)
+
+			calls := gate.AwaitArrivals(2)
+			calls[0].Release(1, nil)
+			calls[1].Release(1, nil)
+			for i := 0; i < 2; i++ {
+				if result := <-results; result != (gateResult{1, nil}) {
+					t.Errorf("Expected released values, got %v", result)
+				}
+			}
+		})
+	}
+}
+
+func TestGate_TimesOut(t *testing.T) {
+	sleeper := func(d time.Duration) <-chan time.Time {
+		c := make(chan time.Time, 1)
+		c <- time.Now()
+		return c
+	}
+
+	gate := NewGate()
+	gate.SetTimeout(time.Second, sleeper)
+	if _, err := gate.Receive(); err == nil {
+		t.Errorf("Expected error for invocation that was not received")
+	}
+
+	gate.SetTimeout(50 * time.Millisecond)
+	go func() {
+		<-gate.Arrivals()
+	}()
+	if _, err := gate.Receive(); err == nil {
+		t.Errorf("Expected error for invocation that was not released")
+	}
+}
+
+func TestGate_FatallyFailsTheTest(t *testing.T) {
+	testMethod, _ := reflect.TypeOf((*api)(nil)).Elem().MethodByName("test")
+	callMethod, _ := reflect.TypeOf((*api)(nil)).Elem().MethodByName("call")
+
+	type test struct {
+		name        string
+		method      reflect.Method
+		gate        func(gate Gate)
+		expectedMsg string
+	}
+
+	tests := []test{
+		{"AwaitArrivals", testMethod, func(gate Gate) {
+			gate.SetTimeout(time.Millisecond)
+			gate.AwaitArrivals(1)
+		}, `timed out waiting for 1 arrivals`},
+		{"ReleaseValues", testMethod, func(gate Gate) {
+			go gate.Receive()
+			gate.Release("not an int", nil)
+		}, `string`},
+		{"FailWithoutError", callMethod, func(gate Gate) {
+			go gate.Receive()
+			gate.Fail(errors.New("unavailable"))
+		}, `does not return an error`},
+		{"AlreadyReleased", testMethod, func(gate Gate) {
+			go gate.Receive()
+			call := gate.AwaitArrivals(1)[0]
+			call.Release(1, nil)
+			call.Release(1, nil)
+		}, `GateCall\(1\) has already been released`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			gate := NewGate()
+			NewReturnsForMethod(tDouble, test.method, gate)
+			test.gate(gate)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
@@ -0,0 +1,290 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// updateGoldenEnv is the environment variable that requests golden files are (re)written
+const updateGoldenEnv = "GODOUBLE_UPDATE"
+
+// updatingGoldenFiles is whether the GODOUBLE_UPDATE environment variable is true, or the test binary has
+// defined and set its own -update flag. The library does not register flags itself.
+func updatingGoldenFiles() bool {
+	if update, err := strconv.ParseBool(os.Getenv(updateGoldenEnv)); err == nil && update {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		update, _ := strconv.ParseBool(f.Value.String())
+		return update
+	}
+	return false
+}
+
+/*
+A Transcript records the calls made to all the TestDoubles that are configured to Record to it,
+in the order they were invoked.
+
+Setup phase
+
+ tr := NewTranscript()
+ d1 := NewAPIDouble(t, tr.Record)
+ d2 := NewOtherDouble(t, tr.Record)
+
+Verify phase
+
+ tr.VerifyGolden(t) // compares with testdata/<TestName>.golden
+
+Run tests with GODOUBLE_UPDATE=true (or with an -update flag defined by the test package) to (re)write the golden files.
+*/
+type Transcript struct {
+	mutex   sync.Mutex
+	names   map[*TestDouble]string
+	counts  map[string]int
+	entries []*transcriptEntry
+}
+
+type transcriptEntry struct {
+	*recordedCall
+	double string
+	method string
+}
+
+// NewTranscript creates an empty Transcript
+func NewTranscript() *Transcript {
+	return &Transcript{names: make(map[*TestDouble]string), counts: make(map[string]int)}
+}
+
+/*
+Record is a TestDouble configurator that records all calls to d in this transcript.
+
+Calls are labelled with the interface type of d. Second and subsequent doubles for the same interface
+are distinguished with a numeric suffix in order of creation, eg examples.API#2
+*/
+func (tr *Transcript) Record(d *TestDouble) {
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	if _, found := tr.names[d]; found {
+		return
+	}
+	label := d.forInterface.String()
+	tr.counts[label]++
+	if count := tr.counts[label]; count > 1 {
+		label = fmt.Sprintf("%s#%d", label, count)
+	}
+	tr.names[d] = label
+	d.transcript = tr
+}
+
+func (tr *Transcript) record(m *method, args []interface{}) {
+	call := m.newRecordedCall(args)
+	tr.mutex.Lock()
+	defer tr.mutex.Unlock()
+	tr.entries = append(tr.entries, &transcriptEntry{recordedCall: call, double: tr.names[m.receiver], method: m.m.Name})
+}
+
+// Lines returns the transcript of recorded calls, one call per line in the order they were invoked
+func (tr *Transcript) Lines() []string {
+	tr.mutex.Lock()
+	entries := make([]*transcriptEntry, len(tr.entries))
+	copy(entries, tr.entries)
+	tr.mutex.Unlock()
+
+	//Concurrent calls may have been appended out of order
+	sort.Slice(entries, func(i, j int) bool { return entries[i].tick < entries[j].tick })
+
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		args := make([]string, len(entry.args))
+		for j, arg := range entry.args {
+			args[j] = formatArg(arg)
+		}
+		lines[i] = fmt.Sprintf("%s.%s(%s)", entry.double, entry.method, strings.Join(args, ", "))
+	}
+	return lines
+}
+
+func (tr *Transcript) String() string {
+	return strings.Join(tr.Lines(), "\n")
+}
+
+/*
+VerifyGolden asserts the transcript matches the content of goldenFile, reporting a unified diff on mismatch.
+
+The default goldenFile is testdata/<t.Name()>.golden, which requires t to provide Name() (as testing.T does).
+
+If GODOUBLE_UPDATE=true, or the test package defines an -update flag that is set, the goldenFile is (re)written instead.
+*/
+func (tr *Transcript) VerifyGolden(t T, goldenFile ...string) {
+	t.Helper()
+	var path string
+	if len(goldenFile) > 0 {
+		path = goldenFile[0]
+	} else if named, isNamed := t.(interface{ Name() string }); isNamed {
+		path = filepath.Join("testdata", strings.Replace(named.Name(), "/", "__", -1)+".golden")
+	} else {
+		t.Fatalf("VerifyGolden requires an explicit golden file for %T without Name()", t)
+		return
+	}
+
+	actual := tr.Lines()
+	content := strings.Join(actual, "\n") + "\n"
+
+	if updatingGoldenFiles() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Cannot create directory for golden file %s: %s", path, err.Error())
+		} else if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Cannot write golden file %s: %s", path, err.Error())
+		} else {
+			t.Logf("Updated golden file %s with %d calls", path, len(actual))
+		}
+		return
+	}
+
+	golden, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Errorf("Cannot read golden file %s (run with GODOUBLE_UPDATE=true to create it): %s", path, err.Error())
+		return
+	}
+
+	expected := strings.Split(strings.TrimSuffix(string(golden), "\n"), "\n")
+	if len(golden) == 0 {
+		expected = nil
+	}
+	if diff := unifiedDiff(path, "recorded calls", expected, actual); diff != "" {
+		t.Errorf("Recorded calls do not match golden file %s (run with GODOUBLE_UPDATE=true to accept)\n%s", path, diff)
+	}
+}
+
+const maxFormatDepth = 10
+
+var timeType = reflect.TypeOf(time.Time{})
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// formatArg renders arg in a stable (address free, sorted map keys) single line format
+func formatArg(arg interface{}) string {
+	sb := &strings.Builder{}
+	formatValue(sb, reflect.ValueOf(arg), 0)
+	return sb.String()
+}
+
+func formatValue(sb *strings.Builder, v reflect.Value, depth int) {
+	if !v.IsValid() {
+		sb.WriteString("nil")
+		return
+	}
+	if depth > maxFormatDepth {
+		sb.WriteString("...")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+	}
+
+	if v.CanInterface() {
+		switch {
+		case v.Type() == timeType:
+			sb.WriteString(v.Interface().(time.Time).Round(0).Format(time.RFC3339Nano))
+			return
+		case v.Kind() != reflect.Interface && v.Type().Implements(errorType):
+			fmt.Fprintf(sb, "error(%q)", v.Interface().(error).Error())
+			return
+		case v.Kind() != reflect.Interface && v.Kind() != reflect.Ptr && v.Type().Implements(stringerType):
+			sb.WriteString(v.Interface().(fmt.Stringer).String())
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		sb.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sb.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		sb.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		sb.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+	case reflect.Complex64:
+		sb.WriteString(fmt.Sprint(complex64(v.Complex())))
+	case reflect.Complex128:
+		sb.WriteString(fmt.Sprint(v.Complex()))
+	case reflect.String:
+		sb.WriteString(strconv.Quote(v.String()))
+	case reflect.Ptr:
+		sb.WriteRune('&')
+		formatValue(sb, v.Elem(), depth+1)
+	case reflect.Interface:
+		formatValue(sb, v.Elem(), depth)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			fmt.Fprintf(sb, "%s(%q)", v.Type(), v.Bytes())
+			return
+		}
+		sb.WriteRune('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			formatValue(sb, v.Index(i), depth+1)
+		}
+		sb.WriteRune(']')
+	case reflect.Map:
+		entries := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entry := &strings.Builder{}
+			formatValue(entry, iter.Key(), depth+1)
+			entry.WriteString(": ")
+			formatValue(entry, iter.Value(), depth+1)
+			entries = append(entries, entry.String())
+		}
+		sort.Strings(entries)
+		fmt.Fprintf(sb, "map[%s]", strings.Join(entries, ", "))
+	case reflect.Struct:
+		sb.WriteString(v.Type().String())
+		sb.WriteRune('{')
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(v.Type().Field(i).Name)
+			sb.WriteString(": ")
+			formatValue(sb, v.Field(i), depth+1)
+		}
+		sb.WriteRune('}')
+	default:
+		//func, chan, unsafe pointers have no stable representation
+		fmt.Fprintf(sb, "(%s)", v.Type())
+	}
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTranscript_RecordsCallsToAllDoubles(t *testing.T) {
+	tr := NewTranscript()
+	d1 := newApiDouble(t, tr.Record)
+	d2 := newApiDouble(t, tr.Record)
+	d1.Stub("call")
+	d1.Stub("test")
+	d2.Stub("call")
+	d2.Stub("empty")
+
+	d1.call("first")
+	d2.call("second")
+	d2.empty()
+	_, _ = d1.test(10, "x")
+
+	expected := []string{
+		`godouble.api.call("first")`,
+		`godouble.api#2.call("second")`,
+		`godouble.api#2.empty()`,
+		`godouble.api.test(10, "x")`,
+	}
+	if actual := tr.Lines(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestTranscript_VerifyGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	goldenFile := filepath.Join(dir, "testdata", "transcript.golden")
+
+	exercise := func(t T, last string) *Transcript {
+		tr := NewTranscript()
+		d := newApiDouble(t, tr.Record, func(d *TestDouble) { d.DisableTrace() })
+		d.Stub("call")
+		for _, s := range []string{"one", "two", "three", "four", "five", last} {
+			d.call(s)
+		}
+		return tr
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		doubleT := NewTDouble(t)
+		spy := doubleT.Spy("Errorf")
+		exercise(doubleT, "six").VerifyGolden(doubleT, goldenFile)
+		spy.Matching(printfMatcher(`GODOUBLE_UPDATE=true`)).Expect(Once())
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		if err := os.Setenv(updateGoldenEnv, "true"); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Unsetenv(updateGoldenEnv)
+		exercise(t, "six").VerifyGolden(t, goldenFile)
+		if content, err := ioutil.ReadFile(goldenFile); err != nil {
+			t.Errorf("Expected golden file to be written, got %v", err)
+		} else {
+			assertMatch(t, string(content), `(?s)^godouble.api.call\("one"\)\n.*call\("six"\)\n$`)
+		}
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		exercise(t, "six").VerifyGolden(t, goldenFile)
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		doubleT := NewTDouble(t)
+		spy := doubleT.Spy("Errorf")
+		exercise(doubleT, "seven").VerifyGolden(doubleT, goldenFile)
+		spy.Matching(printfMatcher(`(?s)do not match.*@@ -3,4 \+3,4 @@\n.*-godouble.api.call\("six"\)\n\+godouble.api.call\("seven"\)\n$`)).Expect(Once())
+	})
+}
+
+func TestTranscript_DoesNotRegisterUpdateFlag(t *testing.T) {
+	//Test packages commonly define their own -update flag, which would panic if already defined
+	if f := flag.Lookup("update"); f != nil {
+		t.Errorf("Expected no -update flag to be registered, got %v", f.Usage)
+	}
+}
+
+func TestTranscript_VerifyGoldenRequiresNameOrFile(t *testing.T) {
+	tDouble := NewTDouble(t)
+	spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+	defer func(spy FakeMethodCall) {
+		recover()
+		spy.Matching(printfMatcher(`explicit golden file`)).Expect(Once())
+	}(spy)
+
+	NewTranscript().VerifyGolden(tDouble)
+	t.Errorf("Expect unreachable")
+}
+
+func TestFormatArg(t *testing.T) {
+	type inner struct {
+		Name  string
+		count int
+	}
+	var nilPtr *inner
+	when := time.Date(2020, 2, 15, 20, 35, 3, 0, time.UTC)
+
+	tests := []struct {
+		arg      interface{}
+		expected string
+	}{
+		{nil, "nil"},
+		{"quoted\n", `"quoted\n"`},
+		{-12, "-12"},
+		{uint8(7), "7"},
+		{1.5, "1.5"},
+		{float32(0.1), "0.1"},
+		{complex64(0.1 + 1i), "(0.1+1i)"},
+		{true, "true"},
+		{nilPtr, "nil"},
+		{&inner{"x", 2}, `&godouble.inner{Name: "x", count: 2}`},
+		{[]int{1, 2}, "[1, 2]"},
+		{[]byte("abc"), `[]uint8("abc")`},
+		{map[string]int{"b": 2, "a": 1}, `map["a": 1, "b": 2]`},
+		{errors.New("failed"), `error("failed")`},
+		{time.Second, "1s"},
+		{when, "2020-02-15T20:35:03Z"},
+		{func() {}, "(func())"},
+	}
+
+	for _, test := range tests {
+		if actual := formatArg(test.arg); actual != test.expected {
+			t.Errorf("Expected formatArg(%#v) to be %s, got %s", test.arg, test.expected, actual)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	a := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13"}
+	b := []string{"1", "2", "3", "4", "5", "six", "7", "8", "9", "10", "11", "12", "13", "14"}
+
+	expected := `--- a
++++ b
+@@ -3,7 +3,7 @@
+ 3
+ 4
+ 5
+-6
++six
+ 7
+ 8
+ 9
+@@ -11,3 +11,4 @@
+ 11
+ 12
+ 13
++14
+`
+	if actual := unifiedDiff("a", "b", a, b); actual != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, actual)
+	}
+
+	if actual := unifiedDiff("a", "b", a, a); actual != "" {
+		t.Errorf("Expected no diff, got %s", actual)
+	}
+}
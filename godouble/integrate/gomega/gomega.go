@@ -0,0 +1,108 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package gomega allows gomega matchers (github.com/onsi/gomega/types.GomegaMatcher) to be used as godouble
+argument matchers.
+
+The gomega interface is declared here by method set so this package does not depend on gomega itself.
+
+Import with an alias to avoid clashing with the gomega package itself, then in the Setup phase:
+ import gdgomega "github.com/lwoggardner/godouble/godouble/integrate/gomega"
+
+ d := NewAPIDouble(t, gdgomega.Integrate)
+ d.Stub("Call").Matching(HavePrefix("x"), BeNumerically(">", 0)).Returning(1)
+*/
+package gomega
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/lwoggardner/godouble/godouble"
+)
+
+// GomegaMatcher has the method set of gomega's types.GomegaMatcher
+type GomegaMatcher interface {
+	Match(actual interface{}) (success bool, err error)
+	FailureMessage(actual interface{}) (message string)
+	NegatedFailureMessage(actual interface{}) (message string)
+}
+
+type adapter struct {
+	matcher GomegaMatcher
+}
+
+func (a adapter) String() string {
+	if stringer, isStringer := a.matcher.(fmt.Stringer); isStringer {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%T", a.matcher)
+}
+
+func (a adapter) Matches(args ...interface{}) bool {
+	success, err := a.matcher.Match(args[0])
+	return success && err == nil
+}
+
+// Explain returns the gomega FailureMessage, or the error returned by Match
+func (a adapter) Explain(args ...interface{}) (bool, string) {
+	success, err := a.matcher.Match(args[0])
+	switch {
+	case err != nil:
+		return false, err.Error()
+	case !success:
+		return false, a.matcher.FailureMessage(args[0])
+	default:
+		return true, ""
+	}
+}
+
+// ForType accepts any type as gomega matchers are not typed
+func (a adapter) ForType(t godouble.T, ft reflect.Type) {}
+
+// Adapt wraps a gomega matcher as a godouble SingleArgMatcher
+func Adapt(m GomegaMatcher) godouble.SingleArgMatcher {
+	return adapter{m}
+}
+
+// adapt converts gomega matchers in a list of godouble matchers, leaving other values as is
+func adapt(matchers []interface{}) []interface{} {
+	adapted := make([]interface{}, len(matchers))
+	for i, m := range matchers {
+		if gomegaMatcher, isGomega := m.(GomegaMatcher); isGomega {
+			adapted[i] = Adapt(gomegaMatcher)
+		} else {
+			adapted[i] = m
+		}
+	}
+	return adapted
+}
+
+// MatcherForMethod is a godouble.MatcherForMethod that accepts gomega matchers anywhere godouble accepts a
+// single argument matcher
+func MatcherForMethod(t godouble.T, m reflect.Method, chained godouble.MethodArgsMatcher, matchers ...interface{}) godouble.MethodArgsMatcher {
+	result := godouble.NewMatcherForMethod(t, m, adapt(matchers)...)
+	if chained != nil {
+		result = godouble.And(chained, result)
+	}
+	return result
+}
+
+// Integrate is a TestDouble configurator that sets MatcherForMethod as the matcher integration
+func Integrate(d *godouble.TestDouble) {
+	d.SetMatcherIntegration(MatcherForMethod)
+}
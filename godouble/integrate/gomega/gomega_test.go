@@ -0,0 +1,114 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gomega
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lwoggardner/godouble/godouble"
+)
+
+// havePrefixMatcher mimics gomega's HavePrefix
+type havePrefixMatcher struct {
+	prefix string
+}
+
+func (h havePrefixMatcher) Match(actual interface{}) (bool, error) {
+	s, isString := actual.(string)
+	if !isString {
+		return false, errors.New("HavePrefix matcher requires a string")
+	}
+	return strings.HasPrefix(s, h.prefix), nil
+}
+
+func (h havePrefixMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected\n    <string>: %s\nto have prefix\n    <string>: %s", actual, h.prefix)
+}
+
+func (h havePrefixMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected\n    <string>: %s\nnot to have prefix\n    <string>: %s", actual, h.prefix)
+}
+
+type api interface {
+	Call(s string, i int) int
+}
+
+type apiDouble struct {
+	api
+	*godouble.TestDouble
+}
+
+func (d *apiDouble) Call(s string, i int) int {
+	return d.Invoke("Call", s, i)[0].(int)
+}
+
+type recordingT struct {
+	*testing.T
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMatcherForMethod(t *testing.T) {
+	d := &apiDouble{TestDouble: godouble.NewDouble(t, (*api)(nil), Integrate)}
+	d.Stub("Call").Matching(havePrefixMatcher{"x"}, godouble.GreaterThan(0)).Returning(1)
+	d.Stub("Call").Returning(2)
+
+	for _, test := range []struct {
+		s        string
+		i        int
+		expected int
+	}{{"xyz", 10, 1}, {"xyz", 0, 2}, {"abc", 1, 2}} {
+		if r := d.Call(test.s, test.i); r != test.expected {
+			t.Errorf("Expected Call(%s,%d) to return %d, got %d", test.s, test.i, test.expected, r)
+		}
+	}
+}
+
+func TestAdapt_Explain(t *testing.T) {
+	matcher := Adapt(havePrefixMatcher{"x"})
+	if matched, explanation := matcher.(interface {
+		Explain(args ...interface{}) (bool, string)
+	}).Explain("abc"); matched || explanation != "Expected\n    <string>: abc\nto have prefix\n    <string>: x" {
+		t.Errorf("Expected gomega failure message, got %v %q", matched, explanation)
+	}
+	if _, explanation := matcher.(interface {
+		Explain(args ...interface{}) (bool, string)
+	}).Explain(1); explanation != "HavePrefix matcher requires a string" {
+		t.Errorf("Expected gomega error, got %q", explanation)
+	}
+	if s := fmt.Sprint(matcher); s != "gomega.havePrefixMatcher" {
+		t.Errorf("Expected type name as description, got %s", s)
+	}
+}
+
+func TestAdapt_ExplainsInFailures(t *testing.T) {
+	recorder := &recordingT{T: t}
+	d := &apiDouble{TestDouble: godouble.NewDouble(recorder, (*api)(nil), Integrate, func(d *godouble.TestDouble) { d.DisableTrace() })}
+	spy := d.Spy("Call")
+	d.Call("abc", 1)
+
+	spy.Where(havePrefixMatcher{"x"}, godouble.All()).All()
+	if len(recorder.errors) != 1 || !strings.Contains(recorder.errors[0], "arg[0]: Expected\n") {
+		t.Errorf("Expected gomega failure message, got %q", recorder.errors)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package gomock allows gomock matchers (github.com/golang/mock/gomock) to be used as godouble argument matchers.
+
+The gomock interfaces are declared here by method set so this package does not depend on gomock itself.
+
+Import with an alias to avoid clashing with the gomock package itself, then in the Setup phase:
+ import gdmock "github.com/lwoggardner/godouble/godouble/integrate/gomock"
+
+ d := NewAPIDouble(t, gdmock.Integrate)
+ d.Stub("Call").Matching(gomock.Eq("x"), gomock.Any()).Returning(1)
+*/
+package gomock
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/lwoggardner/godouble/godouble"
+)
+
+// Matcher has the method set of gomock.Matcher
+type Matcher interface {
+	Matches(x interface{}) bool
+	String() string
+}
+
+// GotFormatter has the method set of gomock.GotFormatter, optionally used to format the actual value on failure
+type GotFormatter interface {
+	Got(got interface{}) string
+}
+
+type adapter struct {
+	matcher Matcher
+}
+
+func (a adapter) String() string {
+	return a.matcher.String()
+}
+
+func (a adapter) Matches(args ...interface{}) bool {
+	return a.matcher.Matches(args[0])
+}
+
+// Explain describes a mismatch in the same form as gomock's own failure messages
+func (a adapter) Explain(args ...interface{}) (bool, string) {
+	if a.matcher.Matches(args[0]) {
+		return true, ""
+	}
+	got := fmt.Sprintf("%v (%T)", args[0], args[0])
+	if formatter, isFormatter := a.matcher.(GotFormatter); isFormatter {
+		got = formatter.Got(args[0])
+	}
+	return false, fmt.Sprintf("Got: %s\nWant: %s", got, a.matcher)
+}
+
+// ForType accepts any type as gomock matchers are not typed
+func (a adapter) ForType(t godouble.T, ft reflect.Type) {}
+
+// Adapt wraps a gomock matcher as a godouble SingleArgMatcher
+func Adapt(m Matcher) godouble.SingleArgMatcher {
+	return adapter{m}
+}
+
+// adapt converts gomock matchers in a list of godouble matchers, leaving other values (including godouble matchers) as is
+func adapt(matchers []interface{}) []interface{} {
+	adapted := make([]interface{}, len(matchers))
+	for i, m := range matchers {
+		_, isGodouble := m.(godouble.Matcher)
+		if gomockMatcher, isGomock := m.(Matcher); isGomock && !isGodouble {
+			adapted[i] = Adapt(gomockMatcher)
+		} else {
+			adapted[i] = m
+		}
+	}
+	return adapted
+}
+
+// MatcherForMethod is a godouble.MatcherForMethod that accepts gomock matchers anywhere godouble accepts a
+// single argument matcher
+func MatcherForMethod(t godouble.T, m reflect.Method, chained godouble.MethodArgsMatcher, matchers ...interface{}) godouble.MethodArgsMatcher {
+	result := godouble.NewMatcherForMethod(t, m, adapt(matchers)...)
+	if chained != nil {
+		result = godouble.And(chained, result)
+	}
+	return result
+}
+
+// Integrate is a TestDouble configurator that sets MatcherForMethod as the matcher integration
+func Integrate(d *godouble.TestDouble) {
+	d.SetMatcherIntegration(MatcherForMethod)
+}
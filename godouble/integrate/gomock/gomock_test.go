@@ -0,0 +1,100 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gomock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lwoggardner/godouble/godouble"
+)
+
+// eqMatcher mimics gomock.Eq
+type eqMatcher struct {
+	x interface{}
+}
+
+func (e eqMatcher) Matches(x interface{}) bool {
+	return e.x == x
+}
+
+func (e eqMatcher) String() string {
+	return fmt.Sprintf("is equal to %v (%T)", e.x, e.x)
+}
+
+// anyMatcher mimics gomock.Any
+type anyMatcher struct{}
+
+func (anyMatcher) Matches(interface{}) bool {
+	return true
+}
+
+func (anyMatcher) String() string {
+	return "is anything"
+}
+
+type api interface {
+	Call(s string, i int) int
+}
+
+type apiDouble struct {
+	api
+	*godouble.TestDouble
+}
+
+func (d *apiDouble) Call(s string, i int) int {
+	return d.Invoke("Call", s, i)[0].(int)
+}
+
+type recordingT struct {
+	*testing.T
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMatcherForMethod(t *testing.T) {
+	d := &apiDouble{TestDouble: godouble.NewDouble(t, (*api)(nil), Integrate)}
+	d.Stub("Call").Matching(eqMatcher{"x"}, anyMatcher{}).Returning(1)
+	d.Stub("Call").Matching(godouble.HasPrefix("y"), eqMatcher{2}).Returning(2)
+	d.Stub("Call").Returning(3)
+
+	for _, test := range []struct {
+		s        string
+		i        int
+		expected int
+	}{{"x", 10, 1}, {"yes", 2, 2}, {"yes", 3, 3}, {"z", 1, 3}} {
+		if r := d.Call(test.s, test.i); r != test.expected {
+			t.Errorf("Expected Call(%s,%d) to return %d, got %d", test.s, test.i, test.expected, r)
+		}
+	}
+}
+
+func TestAdapt_ExplainsWithGomockMessage(t *testing.T) {
+	recorder := &recordingT{T: t}
+	d := &apiDouble{TestDouble: godouble.NewDouble(recorder, (*api)(nil), Integrate, func(d *godouble.TestDouble) { d.DisableTrace() })}
+	spy := d.Spy("Call")
+	d.Call("x", 1)
+
+	spy.Where(eqMatcher{"y"}, anyMatcher{}).All()
+	if len(recorder.errors) != 1 || !strings.HasSuffix(recorder.errors[0], "arg[0]: Got: x (string)\n      Want: is equal to y (string)") {
+		t.Errorf("Expected gomock failure message, got %q", recorder.errors)
+	}
+}
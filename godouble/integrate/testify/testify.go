@@ -0,0 +1,164 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+/*
+Package testify allows testify mock argument matchers (github.com/stretchr/testify/mock) to be used as godouble
+argument matchers, with other values compared as per assert.ObjectsAreEqual.
+
+The testify types are recognised by method set and name so this package does not depend on testify itself.
+
+ mock.Anything              matches any value
+ mock.AnythingOfType("T")   matches values whose type name is T
+ mock.MatchedBy(fn)         (or any value with Matches(interface{}) bool) uses its Matches method
+
+Testify v1 is supported, both before and after v1.8.3, where AnythingOfTypeArgument became an alias of the
+unexported anythingOfTypeArgument.
+
+mock.IsType is not supported because it holds its type in an unexported field. Use godouble.IsA(v) instead.
+
+Import with an alias to avoid clashing with the testify package itself, then in the Setup phase:
+ import gdtestify "github.com/lwoggardner/godouble/godouble/integrate/testify"
+
+ d := NewAPIDouble(t, gdtestify.Integrate)
+ d.Stub("Call").Matching(mock.Anything, mock.MatchedBy(func(i int) bool { return i > 0 })).Returning(1)
+*/
+package testify
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/lwoggardner/godouble/godouble"
+)
+
+// Anything is the value of testify's mock.Anything
+const Anything = "mock.Anything"
+
+// ArgumentMatcher has the method set of testify's mock.ArgumentMatcher
+type ArgumentMatcher interface {
+	Matches(argument interface{}) bool
+}
+
+type adapter struct {
+	desc  string
+	match func(actual interface{}) bool
+}
+
+func (a adapter) String() string {
+	return a.desc
+}
+
+func (a adapter) Matches(args ...interface{}) bool {
+	return a.match(args[0])
+}
+
+// Explain describes a mismatch in the same form as testify's argument diff
+func (a adapter) Explain(args ...interface{}) (bool, string) {
+	if a.match(args[0]) {
+		return true, ""
+	}
+	return false, fmt.Sprintf("%s != (%[2]T=%#[2]v)", a.desc, args[0])
+}
+
+// ForType accepts any type as testify matchers are not typed
+func (a adapter) ForType(t godouble.T, ft reflect.Type) {}
+
+// Adapt wraps a testify ArgumentMatcher as a godouble SingleArgMatcher
+func Adapt(m ArgumentMatcher) godouble.SingleArgMatcher {
+	desc := fmt.Sprintf("%v", m)
+	if _, isStringer := m.(fmt.Stringer); !isStringer {
+		desc = fmt.Sprintf("%T", m)
+	}
+	return adapter{desc, m.Matches}
+}
+
+// ObjectsAreEqual matches a single argument equal to expected as per testify's assert.ObjectsAreEqual
+//
+// ie reflect.DeepEqual, except []byte values are compared with bytes.Equal
+func ObjectsAreEqual(expected interface{}) godouble.SingleArgMatcher {
+	return adapter{fmt.Sprintf("(%[1]T=%#[1]v)", expected), func(actual interface{}) bool {
+		expectedBytes, isBytes := expected.([]byte)
+		if !isBytes {
+			return reflect.DeepEqual(expected, actual)
+		}
+		actualBytes, isBytes := actual.([]byte)
+		return isBytes && bytes.Equal(expectedBytes, actualBytes)
+	}}
+}
+
+// anythingOfType matches testify's mock.AnythingOfType, a string type named AnythingOfTypeArgument, or
+// anythingOfTypeArgument since v1.8.3
+func anythingOfType(m interface{}) (string, bool) {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+	switch v.Type().Name() {
+	case "AnythingOfTypeArgument", "anythingOfTypeArgument":
+		return v.String(), true
+	}
+	return "", false
+}
+
+// adapt converts testify matchers and plain values in a list of godouble matchers, leaving godouble matchers,
+// funcs and reflect.Types as is
+func adapt(matchers []interface{}) []interface{} {
+	adapted := make([]interface{}, len(matchers))
+	for i, m := range matchers {
+		if typeName, isAnythingOfType := anythingOfType(m); isAnythingOfType {
+			adapted[i] = adapter{fmt.Sprintf("AnythingOfType(%s)", typeName), func(actual interface{}) bool {
+				at := reflect.TypeOf(actual)
+				return at != nil && (at.Name() == typeName || at.String() == typeName)
+			}}
+			continue
+		}
+		switch typed := m.(type) {
+		case godouble.Matcher, reflect.Type:
+			adapted[i] = m
+		case ArgumentMatcher:
+			adapted[i] = Adapt(typed)
+		case string:
+			if typed == Anything {
+				adapted[i] = godouble.All()
+			} else {
+				adapted[i] = ObjectsAreEqual(m)
+			}
+		default:
+			if m != nil && reflect.TypeOf(m).Kind() == reflect.Func {
+				adapted[i] = m
+			} else {
+				adapted[i] = ObjectsAreEqual(m)
+			}
+		}
+	}
+	return adapted
+}
+
+// MatcherForMethod is a godouble.MatcherForMethod that accepts testify argument matchers anywhere godouble accepts a
+// single argument matcher and compares other values as per assert.ObjectsAreEqual
+func MatcherForMethod(t godouble.T, m reflect.Method, chained godouble.MethodArgsMatcher, matchers ...interface{}) godouble.MethodArgsMatcher {
+	result := godouble.NewMatcherForMethod(t, m, adapt(matchers)...)
+	if chained != nil {
+		result = godouble.And(chained, result)
+	}
+	return result
+}
+
+// Integrate is a TestDouble configurator that sets MatcherForMethod as the matcher integration
+func Integrate(d *godouble.TestDouble) {
+	d.SetMatcherIntegration(MatcherForMethod)
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package testify
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lwoggardner/godouble/godouble"
+)
+
+// anythingOfTypeArgument mimics testify's mock.AnythingOfTypeArgument since v1.8.3, which is an alias of an
+// unexported type
+type anythingOfTypeArgument string
+
+type AnythingOfTypeArgument = anythingOfTypeArgument
+
+// argumentMatcher mimics testify's mock.MatchedBy
+type argumentMatcher struct {
+	fn func(i int) bool
+}
+
+func (a argumentMatcher) Matches(argument interface{}) bool {
+	i, isInt := argument.(int)
+	return isInt && a.fn(i)
+}
+
+func (a argumentMatcher) String() string {
+	return "func(int) bool"
+}
+
+type api interface {
+	Call(s string, i int) int
+}
+
+type apiDouble struct {
+	api
+	*godouble.TestDouble
+}
+
+func (d *apiDouble) Call(s string, i int) int {
+	return d.Invoke("Call", s, i)[0].(int)
+}
+
+type recordingT struct {
+	*testing.T
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+type bytesApi interface {
+	Write(b []byte) int
+}
+
+type bytesApiDouble struct {
+	bytesApi
+	*godouble.TestDouble
+}
+
+func (d *bytesApiDouble) Write(b []byte) int {
+	return d.Invoke("Write", b)[0].(int)
+}
+
+func TestMatcherForMethod(t *testing.T) {
+	d := &apiDouble{TestDouble: godouble.NewDouble(t, (*api)(nil), Integrate)}
+	positive := argumentMatcher{func(i int) bool { return i > 0 }}
+	d.Stub("Call").Matching("x", positive).Returning(1)
+	d.Stub("Call").Matching(AnythingOfTypeArgument("string"), 0).Returning(2)
+	d.Stub("Call").Matching(Anything, Anything).Returning(3)
+
+	for _, test := range []struct {
+		s        string
+		i        int
+		expected int
+	}{{"x", 10, 1}, {"y", 0, 2}, {"y", 1, 3}} {
+		if r := d.Call(test.s, test.i); r != test.expected {
+			t.Errorf("Expected Call(%s,%d) to return %d, got %d", test.s, test.i, test.expected, r)
+		}
+	}
+}
+
+func TestMatcherForMethod_TypeMatchers(t *testing.T) {
+	//The shape of earlier testify versions
+	type AnythingOfTypeArgument string
+
+	type myString string
+
+	for _, test := range []struct {
+		name        string
+		matcher     interface{}
+		matching    []interface{}
+		notMatching []interface{}
+	}{
+		{"AnythingOfType", AnythingOfTypeArgument("string"), []interface{}{"x"}, []interface{}{1, myString("x"), nil}},
+		{"AnythingOfTypeAlias", anythingOfTypeArgument("testify.myString"), []interface{}{myString("x")}, []interface{}{"x"}},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			matcher := adapt([]interface{}{test.matcher})[0].(godouble.SingleArgMatcher)
+			for _, arg := range test.matching {
+				if !matcher.Matches(arg) {
+					t.Errorf("Expected %v to match %#v", matcher, arg)
+				}
+			}
+			for _, arg := range test.notMatching {
+				if matcher.Matches(arg) {
+					t.Errorf("Expected %v to not match %#v", matcher, arg)
+				}
+			}
+		})
+	}
+}
+
+func TestObjectsAreEqual(t *testing.T) {
+	d := &bytesApiDouble{TestDouble: godouble.NewDouble(t, (*bytesApi)(nil), Integrate)}
+	d.Stub("Write").Matching([]byte{}).Returning(1)
+	d.Stub("Write").Returning(2)
+
+	if r := d.Write(nil); r != 1 {
+		t.Errorf("Expected nil and empty []byte to be equal, got %d", r)
+	}
+	if r := d.Write([]byte("x")); r != 2 {
+		t.Errorf("Expected different []byte to fall through, got %d", r)
+	}
+}
+
+func TestAdapt_ExplainsInFailures(t *testing.T) {
+	recorder := &recordingT{T: t}
+	d := &apiDouble{TestDouble: godouble.NewDouble(recorder, (*api)(nil), Integrate, func(d *godouble.TestDouble) { d.DisableTrace() })}
+	spy := d.Spy("Call")
+	d.Call("abc", -1)
+
+	spy.Where(Anything, argumentMatcher{func(i int) bool { return i > 0 }}).All()
+	if len(recorder.errors) != 1 || !strings.HasSuffix(recorder.errors[0], "arg[1]: func(int) bool != (int=-1)") {
+		t.Errorf("Expected testify failure message, got %q", recorder.errors)
+	}
+}
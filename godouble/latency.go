@@ -0,0 +1,166 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A Latency generates successive delays for DelayedBy
+type Latency interface {
+	Next() time.Duration
+}
+
+type latency struct {
+	mutex *sync.Mutex //Random sources are not safe for concurrent use
+	desc  string
+	next  func() float64 //in nanoseconds
+}
+
+func newLatency(desc string, next func() float64) Latency {
+	return &latency{mutex: &sync.Mutex{}, desc: desc, next: next}
+}
+
+func (l *latency) String() string {
+	return l.desc
+}
+
+func (l *latency) Next() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	next := l.next()
+	if next < 0 || math.IsNaN(next) {
+		return 0
+	}
+	if next > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(next)
+}
+
+// standardNormal generates a normally distributed value with mean 0 and standard deviation 1 via Box-Muller
+func standardNormal(random Random) float64 {
+	u1 := 1 - random.Float64() //(0,1] to avoid log(0)
+	u2 := random.Float64()
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
+
+// UniformLatency generates delays uniformly distributed over [0, max)
+func UniformLatency(max time.Duration, random Random) Latency {
+	return newLatency(fmt.Sprintf("UniformLatency(%v)", max), func() float64 {
+		return random.Float64() * float64(max)
+	})
+}
+
+// NormalLatency generates normally distributed delays, with negative delays treated as zero
+func NormalLatency(mean time.Duration, stddev time.Duration, random Random) Latency {
+	return newLatency(fmt.Sprintf("NormalLatency(%v±%v)", mean, stddev), func() float64 {
+		return float64(mean) + standardNormal(random)*float64(stddev)
+	})
+}
+
+// LogNormalLatency generates long tailed delays whose logarithm is normally distributed around log(median) with
+// standard deviation sigma
+func LogNormalLatency(median time.Duration, sigma float64, random Random) Latency {
+	return newLatency(fmt.Sprintf("LogNormalLatency(%v,%v)", median, sigma), func() float64 {
+		return float64(median) * math.Exp(sigma*standardNormal(random))
+	})
+}
+
+// ExponentialLatency generates exponentially distributed delays with the given mean
+func ExponentialLatency(mean time.Duration, random Random) Latency {
+	return newLatency(fmt.Sprintf("ExponentialLatency(%v)", mean), func() float64 {
+		return -math.Log(1-random.Float64()) * float64(mean)
+	})
+}
+
+// A Percentile is the Latency at or below which P percent of delays fall
+type Percentile struct {
+	P       float64
+	Latency time.Duration
+}
+
+/*
+PercentileLatency generates delays matching a table of percentiles, interpolating linearly between them from a delay
+of zero at the 0th percentile. Delays above the highest percentile are that percentile's Latency. eg
+ PercentileLatency(Seed(1), Percentile{50, 10 * time.Millisecond}, Percentile{99, 200 * time.Millisecond},
+   Percentile{99.9, time.Second})
+*/
+func PercentileLatency(random Random, percentiles ...Percentile) Latency {
+	table := append([]Percentile{{0, 0}}, percentiles...)
+	sort.SliceStable(table, func(i, j int) bool { return table[i].P < table[j].P })
+	return newLatency(fmt.Sprintf("PercentileLatency(%v)", percentiles), func() float64 {
+		p := random.Float64() * 100
+		for i := 1; i < len(table); i++ {
+			if p <= table[i].P {
+				lo, hi := table[i-1], table[i]
+				if hi.P == lo.P {
+					return float64(hi.Latency)
+				}
+				return float64(lo.Latency) + (p-lo.P)/(hi.P-lo.P)*float64(hi.Latency-lo.Latency)
+			}
+		}
+		return float64(table[len(table)-1].Latency)
+	})
+}
+
+// A HistogramBucket counts recorded delays above the previous bucket's UpTo and no more than UpTo
+type HistogramBucket struct {
+	UpTo  time.Duration
+	Count int
+}
+
+/*
+HistogramLatency replays the distribution of a recorded latency histogram, choosing a bucket in proportion to its
+Count and a delay uniformly within that bucket. eg from a metrics dashboard
+ HistogramLatency(Seed(1), HistogramBucket{5 * time.Millisecond, 900}, HistogramBucket{50 * time.Millisecond, 90},
+   HistogramBucket{time.Second, 10})
+*/
+func HistogramLatency(random Random, buckets ...HistogramBucket) Latency {
+	sorted := append([]HistogramBucket(nil), buckets...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].UpTo < sorted[j].UpTo })
+	total := 0
+	for _, bucket := range sorted {
+		total += bucket.Count
+	}
+	return newLatency(fmt.Sprintf("HistogramLatency(%v)", buckets), func() float64 {
+		if total <= 0 {
+			return 0
+		}
+		n := int(random.Int63n(int64(total)))
+		var from time.Duration
+		for _, bucket := range sorted {
+			if n < bucket.Count {
+				return float64(from) + random.Float64()*float64(bucket.UpTo-from)
+			}
+			n -= bucket.Count
+			from = bucket.UpTo
+		}
+		return float64(from)
+	})
+}
+
+// DelayedBy wraps the ReturnValues rv with delays generated by latency
+//
+// An optional sleeper function, defaulting to time.After, can be provided. eg for use with fake clock
+func DelayedBy(rv ReturnValues, latency Latency, sleep ...Timewarp) ReturnValues {
+	return newDelayedReturnValues(rv, latency.Next, sleep...)
+}
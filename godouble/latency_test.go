@@ -0,0 +1,103 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// samplePercentile returns the pth percentile of n delays generated by l
+func samplePercentile(l Latency, n int, p float64) time.Duration {
+	samples := make([]time.Duration, n)
+	for i := range samples {
+		samples[i] = l.Next()
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return samples[int(float64(n-1)*p/100)]
+}
+
+func TestLatencyDistributions(t *testing.T) {
+	ms := time.Millisecond
+	type test struct {
+		name    string
+		latency Latency
+		p       float64
+		min     time.Duration
+		max     time.Duration
+	}
+
+	tests := []test{
+		{"UniformMedian", UniformLatency(100*ms, Seed(1)), 50, 45 * ms, 55 * ms},
+		{"NormalMedian", NormalLatency(100*ms, 10*ms, Seed(1)), 50, 98 * ms, 102 * ms},
+		{"NormalP84", NormalLatency(100*ms, 10*ms, Seed(1)), 84, 108 * ms, 112 * ms},
+		{"NormalNeverNegative", NormalLatency(ms, 100*ms, Seed(1)), 0, 0, 0},
+		{"LogNormalMedian", LogNormalLatency(20*ms, 1, Seed(1)), 50, 18 * ms, 22 * ms},
+		{"LogNormalLongTail", LogNormalLatency(20*ms, 1, Seed(1)), 99, 150 * ms, 250 * ms},
+		{"ExponentialMedian", ExponentialLatency(100*ms, Seed(1)), 50, 63 * ms, 76 * ms},
+		{"PercentileP50", PercentileLatency(Seed(1), Percentile{50, 10 * ms}, Percentile{99, 200 * ms}, Percentile{99.9, time.Second}), 50, 9 * ms, 11 * ms},
+		{"PercentileP99", PercentileLatency(Seed(1), Percentile{99.9, time.Second}, Percentile{50, 10 * ms}, Percentile{99, 200 * ms}), 99, 180 * ms, 220 * ms},
+		{"PercentileMax", PercentileLatency(Seed(1), Percentile{50, 10 * ms}, Percentile{99, 200 * ms}), 100, 100 * ms, 200 * ms},
+		{"HistogramFastBucket", HistogramLatency(Seed(1), HistogramBucket{5 * ms, 900}, HistogramBucket{50 * ms, 90}, HistogramBucket{time.Second, 10}), 85, 0, 5 * ms},
+		{"HistogramSlowBucket", HistogramLatency(Seed(1), HistogramBucket{time.Second, 10}, HistogramBucket{5 * ms, 900}, HistogramBucket{50 * ms, 90}), 95, 5 * ms, 50 * ms},
+		{"HistogramEmpty", HistogramLatency(Seed(1)), 50, 0, 0},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if actual := samplePercentile(test.latency, 10000, test.p); actual < test.min || actual > test.max {
+				t.Errorf("Expected p%v of %v between %v and %v, got %v", test.p, test.latency, test.min, test.max, actual)
+			}
+		})
+	}
+}
+
+func TestLatency_IsReproducible(t *testing.T) {
+	sample := func(seed int64) []time.Duration {
+		l := LogNormalLatency(20*time.Millisecond, 1, Seed(seed))
+		return []time.Duration{l.Next(), l.Next(), l.Next()}
+	}
+	if first, second := sample(7), sample(7); !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected same seed to give same delays %v, got %v", first, second)
+	}
+}
+
+func TestDelayedBy(t *testing.T) {
+	var delays []time.Duration
+	sleeper := func(d time.Duration) <-chan time.Time {
+		delays = append(delays, d)
+		c := make(chan time.Time, 1)
+		c <- time.Now()
+		return c
+	}
+
+	latency := PercentileLatency(Seed(3), Percentile{100, time.Second})
+	rv := DelayedBy(Values(10), latency, sleeper)
+	for i := 0; i < 3; i++ {
+		if returns, err := rv.Receive(); err != nil || returns[0] != 10 {
+			t.Errorf("Expected 10, got %v %v", returns, err)
+		}
+	}
+
+	expected := PercentileLatency(Seed(3), Percentile{100, time.Second})
+	if !reflect.DeepEqual(delays, []time.Duration{expected.Next(), expected.Next(), expected.Next()}) {
+		t.Errorf("Expected delays from latency, got %v", delays)
+	}
+}
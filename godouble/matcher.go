@@ -29,6 +29,62 @@ type Matcher interface {
 	Matches(args ...interface{}) bool
 }
 
+/*
+ExplainingMatcher is a Matcher that can explain why args did not match.
+
+All the built-in matchers are ExplainingMatchers, with combinations (eg Args, All, Any, Not, Slice, Len) composing the
+explanations of their members into a tree. Explanations are included in Mock and Spy failures and in trace output.
+*/
+type ExplainingMatcher interface {
+	Matcher
+
+	//Explain returns whether args match and if not, a description of why not
+	Explain(args ...interface{}) (bool, string)
+}
+
+// Explain returns whether args match m and if not, why not
+//
+// Matchers that are not ExplainingMatchers are explained as "<args> did not match <m>"
+func Explain(m Matcher, args ...interface{}) (bool, string) {
+	if em, isExplaining := m.(ExplainingMatcher); isExplaining {
+		if matched, explanation := em.Explain(args...); matched || explanation != "" {
+			return matched, explanation
+		}
+	}
+	return mismatch(m, args)
+}
+
+// mismatch is the default explanation for matchers that cannot add anything more specific
+func mismatch(m Matcher, args []interface{}) (bool, string) {
+	if m.Matches(args...) {
+		return true, ""
+	}
+	return false, fmt.Sprintf("%s did not match %v", formatArgs(args), m)
+}
+
+// formatArgs formats a single argument as itself, and method arguments as a parenthesised list
+func formatArgs(args []interface{}) string {
+	if len(args) == 1 {
+		return formatArg(args[0])
+	}
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		formatted[i] = formatArg(arg)
+	}
+	return "(" + strings.Join(formatted, ", ") + ")"
+}
+
+// explainAll joins the explanations of each of matchers that args did not match
+func explainAll(matchers []Matcher, args []interface{}) (bool, string) {
+	var explanations []string
+	for _, m := range matchers {
+		if matched, explanation := Explain(m, args...); !matched {
+			explanations = append(explanations, explanation)
+		}
+	}
+	return len(explanations) == 0, strings.Join(explanations, "\n")
+}
+
 // MethodArgsMatcher is a Matcher that can validate usage against a reflect.Method
 type MethodArgsMatcher interface {
 	Matcher
@@ -151,6 +207,10 @@ func (f funcMatcher) Matches(args ...interface{}) bool {
 	inArgs := make([]reflect.Value, len(args))
 	for i, arg := range args {
 		inArgs[i] = reflect.ValueOf(arg)
+		if !inArgs[i].IsValid() && i < f.Type().NumIn() {
+			//untyped nil, eg from a nil interface
+			inArgs[i] = reflect.Zero(f.Type().In(i))
+		}
 	}
 
 	if f.Type().IsVariadic() {
@@ -159,6 +219,10 @@ func (f funcMatcher) Matches(args ...interface{}) bool {
 	return f.Call(inArgs)[0].Interface().(bool)
 }
 
+func (f funcMatcher) Explain(args ...interface{}) (bool, string) {
+	return mismatch(f, args)
+}
+
 //Func returns a matcher from the arbitrary function f
 // Custom matcher methods will generally be a wrapper around Func
 //
@@ -225,6 +289,16 @@ func (l *argumentsMatcher) Matches(args ...interface{}) bool {
 	return true
 }
 
+func (l *argumentsMatcher) Explain(args ...interface{}) (bool, string) {
+	var explanations []string
+	for i := 0; i < len(l.matcherList) && i < len(args); i++ {
+		if matched, explanation := Explain(l.matcherList[i], args[i]); !matched {
+			explanations = append(explanations, fmt.Sprintf("arg[%d]: %s", i, indent(explanation, "  ")))
+		}
+	}
+	return len(explanations) == 0, strings.Join(explanations, "\n")
+}
+
 func (l *argumentsMatcher) ForMethod(t T, m reflect.Method) {
 	t.Helper()
 	methodType := m.Type
@@ -297,6 +371,23 @@ func (sm *sliceMatcher) Matches(args ...interface{}) bool {
 	}
 }
 
+func (sm *sliceMatcher) Explain(args ...interface{}) (bool, string) {
+	v := reflect.ValueOf(args[0])
+	if v.Kind() != reflect.Array && v.Kind() != reflect.Slice {
+		return false, fmt.Sprintf("%v is not a slice or array", describeType(v))
+	}
+	if v.Len() < len(sm.matcherList) {
+		return false, fmt.Sprintf("expected at least %d elements, found %d", len(sm.matcherList), v.Len())
+	}
+	var explanations []string
+	for i := 0; i < len(sm.matcherList); i++ {
+		if matched, explanation := Explain(sm.matcherList[i], v.Index(i).Interface()); !matched {
+			explanations = append(explanations, fmt.Sprintf("element[%d]: %s", i, indent(explanation, "  ")))
+		}
+	}
+	return len(explanations) == 0, strings.Join(explanations, "\n")
+}
+
 func (sm *sliceMatcher) ForType(t T, in reflect.Type) {
 	t.Helper()
 	if in.Kind() != reflect.Slice && in.Kind() != reflect.Array {
@@ -334,6 +425,10 @@ func (n nilMatcher) Matches(args ...interface{}) bool {
 	return false
 }
 
+func (n nilMatcher) Explain(args ...interface{}) (bool, string) {
+	return mismatch(n, args)
+}
+
 func (n nilMatcher) ForType(t T, ft reflect.Type) {
 	t.Helper()
 	switch ft.Kind() {
@@ -370,6 +465,19 @@ func (l lenMatcher) Matches(args ...interface{}) bool {
 	}
 }
 
+func (l lenMatcher) Explain(args ...interface{}) (bool, string) {
+	v := reflect.ValueOf(args[0])
+	switch v.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Map, reflect.Slice, reflect.String:
+		if matched, explanation := Explain(l.SingleArgMatcher, v.Len()); !matched {
+			return false, "len(): " + explanation
+		}
+		return true, ""
+	default:
+		return false, fmt.Sprintf("%v has no length", describeType(v))
+	}
+}
+
 func (l lenMatcher) ForType(t T, ft reflect.Type) {
 	t.Helper()
 	switch ft.Kind() {
@@ -411,15 +519,15 @@ func IsA(t interface{}) SingleArgMatcher {
 
 type combinationMatcher struct {
 	matcherList
-	explain string
+	name string
 }
 
 func (a combinationMatcher) String() string {
-	return a.matcherList.toString(a.explain, '{', '}')
+	return a.matcherList.toString(a.name, '{', '}')
 }
 
-func newCombinationMatcher(matchers []Matcher, explain string) combinationMatcher {
-	return combinationMatcher{matchers, explain}
+func newCombinationMatcher(matchers []Matcher, name string) combinationMatcher {
+	return combinationMatcher{matchers, name}
 }
 
 type andMatcher struct {
@@ -435,6 +543,11 @@ func (a andMatcher) Matches(args ...interface{}) bool {
 	return true
 }
 
+// Explain joins the explanations of the members that did not match
+func (a andMatcher) Explain(args ...interface{}) (bool, string) {
+	return explainAll(a.matcherList, args)
+}
+
 // All matches if all the matcherList match (returns true for no matchers)
 func All(matchers ...Matcher) CombinationMatcher {
 	return andMatcher{newCombinationMatcher(matchers, "All")}
@@ -458,6 +571,21 @@ func (a orMatcher) Matches(arg ...interface{}) bool {
 	return false
 }
 
+// Explain lists the explanations of every member, as none of them matched
+func (a orMatcher) Explain(args ...interface{}) (bool, string) {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "%s did not match any of %v", formatArgs(args), a)
+	for _, m := range a.matcherList {
+		matched, explanation := Explain(m, args...)
+		if matched {
+			return true, ""
+		}
+		sb.WriteString("\n  ")
+		sb.WriteString(indent(explanation, "  "))
+	}
+	return false, sb.String()
+}
+
 // Any matches if any one of matcherList match (returns false for no matchers)
 func Any(matchers ...Matcher) CombinationMatcher {
 	return orMatcher{newCombinationMatcher(matchers, "Any")}
@@ -480,6 +608,13 @@ func (nm notMatcher) Matches(arg ...interface{}) bool {
 	return !nm.Matcher.Matches(arg...)
 }
 
+func (nm notMatcher) Explain(args ...interface{}) (bool, string) {
+	if nm.Matcher.Matches(args...) {
+		return false, fmt.Sprintf("%s matched %v", formatArgs(args), nm.Matcher)
+	}
+	return true, ""
+}
+
 func (nm notMatcher) ForType(t T, ft reflect.Type) {
 	t.Helper()
 	forType(t, ft, nm.Matcher)
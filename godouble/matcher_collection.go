@@ -0,0 +1,420 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+func toMatcherList(matchers []interface{}) matcherList {
+	list := make(matcherList, len(matchers))
+	for i, m := range matchers {
+		list[i] = genericSingleArgumentMatcher(m)
+	}
+	return list
+}
+
+func elementsOf(arg interface{}) ([]interface{}, bool) {
+	v := reflect.ValueOf(arg)
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		elements := make([]interface{}, v.Len())
+		for i := range elements {
+			elements[i] = v.Index(i).Interface()
+		}
+		return elements, true
+	default:
+		return nil, false
+	}
+}
+
+func forElementType(t T, ft reflect.Type, desc fmt.Stringer) (reflect.Type, bool) {
+	t.Helper()
+	switch ft.Kind() {
+	case reflect.Interface:
+		//checked at runtime
+		return nil, false
+	case reflect.Array, reflect.Slice:
+		return ft.Elem(), true
+	default:
+		t.Fatalf("%v used to match non slice or array type %v", desc, ft)
+		return nil, false
+	}
+}
+
+// assignElements finds a distinct element for each matcher (maximum bipartite matching)
+//
+// returns the index of the matchers that could not be assigned an element
+func assignElements(matchers matcherList, elements []interface{}) (unassigned []int) {
+	candidates := make([][]int, len(matchers))
+	for i, m := range matchers {
+		for j, e := range elements {
+			if m.Matches(e) {
+				candidates[i] = append(candidates[i], j)
+			}
+		}
+	}
+
+	assigned := make([]int, len(elements)) //element index => matcher index + 1
+	var augment func(i int, seen []bool) bool
+	augment = func(i int, seen []bool) bool {
+		for _, j := range candidates[i] {
+			if seen[j] {
+				continue
+			}
+			seen[j] = true
+			if assigned[j] == 0 || augment(assigned[j]-1, seen) {
+				assigned[j] = i + 1
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := range matchers {
+		if !augment(i, make([]bool, len(elements))) {
+			unassigned = append(unassigned, i)
+		}
+	}
+	return unassigned
+}
+
+type elementsMatcher struct {
+	matchers matcherList
+	exact    bool
+	desc     string
+}
+
+func (em elementsMatcher) String() string {
+	return em.matchers.toString(em.desc, '(', ')')
+}
+
+func (em elementsMatcher) Matches(args ...interface{}) bool {
+	elements, ok := elementsOf(args[0])
+	if !ok || (em.exact && len(elements) != len(em.matchers)) {
+		return false
+	}
+	return len(assignElements(em.matchers, elements)) == 0
+}
+
+func (em elementsMatcher) Explain(args ...interface{}) (bool, string) {
+	elements, ok := elementsOf(args[0])
+	if !ok {
+		return false, fmt.Sprintf("%T is not a slice or array", args[0])
+	}
+	if em.exact && len(elements) != len(em.matchers) {
+		return false, fmt.Sprintf("expected %d elements, found %d", len(em.matchers), len(elements))
+	}
+	var unmatched []string
+	for _, i := range assignElements(em.matchers, elements) {
+		unmatched = append(unmatched, fmt.Sprint(em.matchers[i]))
+	}
+	if len(unmatched) == 0 {
+		return true, ""
+	}
+	return false, fmt.Sprintf("no distinct element for %s", strings.Join(unmatched, ","))
+}
+
+func (em elementsMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if elemType, ok := forElementType(t, ft, em); ok {
+		em.matchers.ForType(t, elemType)
+	}
+}
+
+// ElementsAnyOrder matches a single slice or array argument whose elements match matchers in any order
+//
+// There must be exactly one element for each matcher. Each matcher may be anything that can match a single argument.
+// eg ElementsAnyOrder("b", "a", HasPrefix("c"))
+func ElementsAnyOrder(matchers ...interface{}) SingleArgMatcher {
+	return elementsMatcher{toMatcherList(matchers), true, "ElementsAnyOrder"}
+}
+
+// ContainsElements matches a single slice or array argument that has a distinct element to match each of matchers
+//
+// Other elements are ignored. Each matcher may be anything that can match a single argument.
+func ContainsElements(matchers ...interface{}) SingleArgMatcher {
+	return elementsMatcher{toMatcherList(matchers), false, "ContainsElements"}
+}
+
+type eachMatcher struct {
+	SingleArgMatcher
+}
+
+func (em eachMatcher) String() string {
+	return fmt.Sprintf("Each(%v)", em.SingleArgMatcher)
+}
+
+func (em eachMatcher) Matches(args ...interface{}) bool {
+	elements, ok := elementsOf(args[0])
+	if !ok {
+		return false
+	}
+	for _, e := range elements {
+		if !em.SingleArgMatcher.Matches(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func (em eachMatcher) Explain(args ...interface{}) (bool, string) {
+	elements, ok := elementsOf(args[0])
+	if !ok {
+		return false, fmt.Sprintf("%T is not a slice or array", args[0])
+	}
+	var explanations []string
+	for i, e := range elements {
+		if matched, explanation := Explain(em.SingleArgMatcher, e); !matched {
+			explanations = append(explanations, fmt.Sprintf("element[%d]: %s", i, indent(explanation, "  ")))
+		}
+	}
+	return len(explanations) == 0, strings.Join(explanations, "\n")
+}
+
+func (em eachMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if elemType, ok := forElementType(t, ft, em); ok {
+		em.SingleArgMatcher.ForType(t, elemType)
+	}
+}
+
+// Each matches a single slice or array argument where every element matches m (trivially true for no elements)
+//
+// m may be anything that can match a single argument. eg Each(GreaterThan(0))
+func Each(m interface{}) SingleArgMatcher {
+	return eachMatcher{genericSingleArgumentMatcher(m)}
+}
+
+type uniqueMatcher struct{}
+
+func (u uniqueMatcher) String() string {
+	return "Unique()"
+}
+
+// duplicate returns the indexes of the first pair of deeply equal elements
+func (u uniqueMatcher) duplicate(elements []interface{}) (int, int, bool) {
+	for i := range elements {
+		for j := 0; j < i; j++ {
+			if reflect.DeepEqual(elements[i], elements[j]) {
+				return j, i, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func (u uniqueMatcher) Matches(args ...interface{}) bool {
+	elements, ok := elementsOf(args[0])
+	if !ok {
+		return false
+	}
+	_, _, found := u.duplicate(elements)
+	return !found
+}
+
+func (u uniqueMatcher) Explain(args ...interface{}) (bool, string) {
+	elements, ok := elementsOf(args[0])
+	if !ok {
+		return false, fmt.Sprintf("%T is not a slice or array", args[0])
+	}
+	if first, second, found := u.duplicate(elements); found {
+		return false, fmt.Sprintf("element[%d] duplicates element[%d] %#v", second, first, elements[first])
+	}
+	return true, ""
+}
+
+func (u uniqueMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	forElementType(t, ft, u)
+}
+
+// Unique matches a single slice or array argument that has no two elements equal via reflect.DeepEqual
+func Unique() SingleArgMatcher {
+	return uniqueMatcher{}
+}
+
+func forMapType(t T, ft reflect.Type, desc fmt.Stringer) (keyType reflect.Type, elemType reflect.Type, ok bool) {
+	t.Helper()
+	switch ft.Kind() {
+	case reflect.Interface:
+		//checked at runtime
+		return nil, nil, false
+	case reflect.Map:
+		return ft.Key(), ft.Elem(), true
+	default:
+		t.Fatalf("%v used to match non map type %v", desc, ft)
+		return nil, nil, false
+	}
+}
+
+type entryMatcher struct {
+	key   SingleArgMatcher
+	value SingleArgMatcher //nil for HasKey
+}
+
+func (em entryMatcher) String() string {
+	if em.value == nil {
+		return fmt.Sprintf("HasKey(%v)", em.key)
+	}
+	return fmt.Sprintf("HasEntry(%v,%v)", em.key, em.value)
+}
+
+func (em entryMatcher) Matches(args ...interface{}) bool {
+	v := reflect.ValueOf(args[0])
+	if v.Kind() != reflect.Map {
+		return false
+	}
+	iter := v.MapRange()
+	for iter.Next() {
+		if em.key.Matches(iter.Key().Interface()) && (em.value == nil || em.value.Matches(iter.Value().Interface())) {
+			return true
+		}
+	}
+	return false
+}
+
+func (em entryMatcher) Explain(args ...interface{}) (bool, string) {
+	return mismatch(em, args)
+}
+
+func (em entryMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if keyType, elemType, ok := forMapType(t, ft, em); ok {
+		em.key.ForType(t, keyType)
+		if em.value != nil {
+			em.value.ForType(t, elemType)
+		}
+	}
+}
+
+// HasKey matches a single map argument that has a key matching k
+//
+// k may be anything that can match a single argument. eg HasKey("id"), HasKey(HasPrefix("x-"))
+func HasKey(k interface{}) SingleArgMatcher {
+	return entryMatcher{key: genericSingleArgumentMatcher(k)}
+}
+
+// HasEntry matches a single map argument that has an entry with key matching k and value matching v
+//
+// k and v may be anything that can match a single argument. eg HasEntry("retries", GreaterThan(0))
+func HasEntry(k interface{}, v interface{}) SingleArgMatcher {
+	return entryMatcher{key: genericSingleArgumentMatcher(k), value: genericSingleArgumentMatcher(v)}
+}
+
+type mapMatcher struct {
+	keys    []interface{}
+	entries map[interface{}]SingleArgMatcher
+}
+
+func (mm mapMatcher) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("MapMatching{")
+	for i, k := range mm.keys {
+		if i > 0 {
+			sb.WriteRune(',')
+		}
+		fmt.Fprintf(&sb, "%#v:%v", k, mm.entries[k])
+	}
+	sb.WriteRune('}')
+	return sb.String()
+}
+
+// mismatch returns a description of the first difference between the map argument and the expected entries
+func (mm mapMatcher) mismatch(arg interface{}) string {
+	v := reflect.ValueOf(arg)
+	if v.Kind() != reflect.Map {
+		return fmt.Sprintf("%T is not a map", arg)
+	}
+	if v.IsNil() && len(mm.keys) > 0 {
+		return "nil map"
+	}
+	for _, k := range mm.keys {
+		kv, ok := mapKey(k, v.Type().Key())
+		if !ok {
+			return fmt.Sprintf("key %#v is not a %v", k, v.Type().Key())
+		}
+		value := v.MapIndex(kv)
+		if !value.IsValid() {
+			return fmt.Sprintf("missing key %#v", k)
+		}
+		if matched, explanation := Explain(mm.entries[k], value.Interface()); !matched {
+			return fmt.Sprintf("key %#v: %s", k, indent(explanation, "  "))
+		}
+	}
+	if v.Len() > len(mm.keys) {
+		return fmt.Sprintf("expected %d entries, found %d", len(mm.keys), v.Len())
+	}
+	return ""
+}
+
+func (mm mapMatcher) Matches(args ...interface{}) bool {
+	return mm.mismatch(args[0]) == ""
+}
+
+func (mm mapMatcher) Explain(args ...interface{}) (bool, string) {
+	reason := mm.mismatch(args[0])
+	return reason == "", reason
+}
+
+func (mm mapMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if keyType, elemType, ok := forMapType(t, ft, mm); ok {
+		for _, k := range mm.keys {
+			if _, ok := mapKey(k, keyType); !ok {
+				t.Fatalf("%v key %#v is not convertible to %v", mm, k, keyType)
+			}
+			mm.entries[k].ForType(t, elemType)
+		}
+	}
+}
+
+// mapKey converts k to a key of keyType, allowing conversion only between types of the same kind (eg string to tstring)
+func mapKey(k interface{}, keyType reflect.Type) (reflect.Value, bool) {
+	kv := reflect.ValueOf(k)
+	switch {
+	case !kv.IsValid():
+		return reflect.Zero(keyType), keyType.Kind() == reflect.Interface
+	case kv.Type().AssignableTo(keyType):
+		return kv, true
+	case kv.Kind() == keyType.Kind() && kv.Type().ConvertibleTo(keyType):
+		return kv.Convert(keyType), true
+	default:
+		return kv, false
+	}
+}
+
+// MapMatching matches a single map argument that has exactly the keys of entries, each with a value matching
+// the corresponding entry
+//
+// Each entry value may be anything that can match a single argument.
+// eg MapMatching(map[interface{}]interface{}{"id": 42, "name": HasPrefix("f")})
+func MapMatching(entries map[interface{}]interface{}) SingleArgMatcher {
+	mm := mapMatcher{entries: make(map[interface{}]SingleArgMatcher, len(entries))}
+	for k, v := range entries {
+		mm.keys = append(mm.keys, k)
+		mm.entries[k] = genericSingleArgumentMatcher(v)
+	}
+	//stable order for descriptions and explanations
+	sort.Slice(mm.keys, func(i, j int) bool {
+		return fmt.Sprintf("%#v", mm.keys[i]) < fmt.Sprintf("%#v", mm.keys[j])
+	})
+	return mm
+}
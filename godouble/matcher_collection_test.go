@@ -0,0 +1,149 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestCollectionMatchers(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		argType     reflect.Type
+		matching    []interface{}
+		notMatching []interface{}
+		re          string
+	}
+
+	strSliceType := reflect.TypeOf([]string{})
+	intSliceType := reflect.TypeOf([]int{})
+	mapType := reflect.TypeOf(map[string]int{})
+	ifaceType := reflect.TypeOf((*interface{})(nil)).Elem()
+
+	tests := []test{
+		{"ElementsAnyOrder", ElementsAnyOrder("b", "a", HasPrefix("c")), strSliceType,
+			[]interface{}{[]string{"a", "b", "cat"}, []string{"cat", "a", "b"}, [3]string{"b", "car", "a"}},
+			[]interface{}{[]string{"a", "b"}, []string{"a", "b", "cat", "dog"}, []string{"a", "a", "cat"}, "abc"},
+			`^ElementsAnyOrder\(Eql\(b\),Eql\(a\),HasPrefix\("c"\)\)$`},
+		{"ElementsAnyOrderOverlapping", ElementsAnyOrder(HasPrefix("a"), "ab"), strSliceType,
+			[]interface{}{[]string{"ab", "ac"}, []string{"ac", "ab"}},
+			[]interface{}{[]string{"ab", "b"}},
+			"ElementsAnyOrder"},
+		{"ContainsElements", ContainsElements(2, GreaterThan(5)), intSliceType,
+			[]interface{}{[]int{1, 2, 3, 6}, []int{6, 2}},
+			[]interface{}{[]int{2}, []int{1, 6}, []int(nil)},
+			`^ContainsElements\(Eql\(2\),GreaterThan\(5\)\)$`},
+		{"ContainsElementsDistinct", ContainsElements(1, 1), intSliceType, []interface{}{[]int{1, 2, 1}}, []interface{}{[]int{1, 2}}, "ContainsElements"},
+		{"Each", Each(GreaterThan(0)), intSliceType, []interface{}{[]int{1, 2}, []int{}}, []interface{}{[]int{1, 0}, 1}, `^Each\(GreaterThan\(0\)\)$`},
+		{"Unique", Unique(), ifaceType, []interface{}{[]int{1, 2, 3}, []string{}, []interface{}{1, "1"}}, []interface{}{[]int{1, 2, 1}, "abc", nil}, `^Unique\(\)$`},
+		{"HasKey", HasKey("id"), mapType, []interface{}{map[string]int{"id": 1}}, []interface{}{map[string]int{"ID": 1}, map[string]int(nil), []string{"id"}}, `^HasKey\(Eql\(id\)\)$`},
+		{"HasKeyMatcher", HasKey(HasPrefix("x-")), mapType, []interface{}{map[string]int{"a": 1, "x-b": 2}}, []interface{}{map[string]int{"a": 1}}, "HasKey"},
+		{"HasEntry", HasEntry("retries", GreaterThan(0)), mapType, []interface{}{map[string]int{"retries": 3}}, []interface{}{map[string]int{"retries": 0}, map[string]int{"other": 3}}, `^HasEntry\(Eql\(retries\),GreaterThan\(0\)\)$`},
+		{"MapMatching", MapMatching(map[interface{}]interface{}{"id": 42, "age": GreaterThan(17)}), mapType,
+			[]interface{}{map[string]int{"id": 42, "age": 18}, map[tstring]int{"id": 42, "age": 30}},
+			[]interface{}{map[string]int{"id": 42}, map[string]int{"id": 42, "age": 10}, map[string]int{"id": 42, "age": 18, "x": 1}, map[int]int{1: 1}},
+			`^MapMatching\{"age":GreaterThan\(17\),"id":Eql\(42\)\}$`},
+		{"MapMatchingEmpty", MapMatching(map[interface{}]interface{}{}), mapType, []interface{}{map[string]int{}, map[string]int(nil)}, []interface{}{map[string]int{"a": 1}}, `^MapMatching\{\}$`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			matcher := test.matcher
+			if !regexp.MustCompile(test.re).MatchString(fmt.Sprint(matcher)) {
+				t.Errorf("expected '%v' to match '%s'", matcher, test.re)
+			}
+
+			matcher.ForType(t, test.argType)
+
+			for _, arg := range test.matching {
+				if !matcher.Matches(arg) {
+					t.Errorf("Expected %s to match %v", matcher, arg)
+				}
+			}
+			for _, notArg := range test.notMatching {
+				if matcher.Matches(notArg) {
+					t.Errorf("Expected %s to not match %v", matcher, notArg)
+				}
+			}
+		})
+	}
+}
+
+func TestCollectionMatchers_FailFatally(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		failType    reflect.Type
+		expectedMsg string
+	}
+
+	mapType := reflect.TypeOf(map[string]int{})
+	tests := []test{
+		{"ElementsNotSlice", ElementsAnyOrder(1), reflect.TypeOf(0), "ElementsAnyOrder.*non slice or array type int"},
+		{"ElementType", ContainsElements(HasPrefix("x")), reflect.TypeOf([]int{}), "HasPrefix.*int.*not a string"},
+		{"EachElementType", Each(GreaterThan(0)), reflect.TypeOf([]string{}), "GreaterThan.*non numeric type string"},
+		{"UniqueNotSlice", Unique(), mapType, `Unique\(\).*non slice or array type map`},
+		{"HasKeyNotMap", HasKey("x"), reflect.TypeOf([]string{}), `HasKey.*non map type \[\]string`},
+		{"HasKeyType", HasKey(GreaterThan(1)), mapType, "GreaterThan.*non numeric type string"},
+		{"HasEntryValueType", HasEntry("x", HasPrefix("y")), mapType, "HasPrefix.*int.*not a string"},
+		{"MapMatchingKeyType", MapMatching(map[interface{}]interface{}{1: 1}), mapType, "MapMatching.*key 1 is not convertible to string"},
+		{"MapMatchingValueType", MapMatching(map[interface{}]interface{}{"a": HasPrefix("x")}), mapType, "HasPrefix.*int.*not a string"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			test.matcher.ForType(tDouble, test.failType)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
+
+func TestCollectionMatchers_Explain(t *testing.T) {
+	tests := []struct {
+		name     string
+		matcher  SingleArgMatcher
+		arg      interface{}
+		expected string
+	}{
+		{"ElementsAnyOrderLength", ElementsAnyOrder(1, 2), []int{1}, "expected 2 elements, found 1"},
+		{"ContainsElements", ContainsElements(1, 3, 4), []int{1, 2}, "no distinct element for Eql(3),Eql(4)"},
+		{"Each", Each(GreaterThan(0)), []int{1, 0}, "element[1]: 0 did not match GreaterThan(0)"},
+		{"Unique", Unique(), []string{"a", "b", "a"}, `element[2] duplicates element[0] "a"`},
+		{"MapMissingKey", MapMatching(map[interface{}]interface{}{"a": 1}), map[string]int{"b": 1}, `missing key "a"`},
+		{"MapValue", MapMatching(map[interface{}]interface{}{"a": 1}), map[string]int{"a": 2}, `key "a": 2 did not match Eql(1)`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if _, explanation := Explain(test.matcher, test.arg); explanation != test.expected {
+				t.Errorf("Expected explanation %q, got %q", test.expected, explanation)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// contextMatcher matches a single non nil context.Context argument
+type contextMatcher struct {
+	desc  string
+	match func(ctx context.Context) bool
+	key   interface{}
+	value SingleArgMatcher //optional matcher for the context value at key
+}
+
+func (cm contextMatcher) String() string {
+	return cm.desc
+}
+
+func (cm contextMatcher) Matches(args ...interface{}) bool {
+	if ctx, isContext := args[0].(context.Context); isContext && ctx != nil {
+		return cm.match(ctx)
+	}
+	return false
+}
+
+func (cm contextMatcher) Explain(args ...interface{}) (bool, string) {
+	if ctx, isContext := args[0].(context.Context); isContext && ctx != nil && cm.value != nil {
+		if matched, explanation := Explain(cm.value, ctx.Value(cm.key)); !matched {
+			return false, fmt.Sprintf("Value(%v): %s", cm.key, indent(explanation, "  "))
+		}
+	}
+	return mismatch(cm, args)
+}
+
+func (cm contextMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if !ft.Implements(contextType) && !(ft.Kind() == reflect.Interface && ft.NumMethod() == 0) {
+		t.Fatalf("%v cannot match type %v which does not implement context.Context", cm, ft)
+	}
+	if cm.value != nil {
+		cm.value.ForType(t, reflect.TypeOf((*interface{})(nil)).Elem())
+	}
+}
+
+// AnyContext matches a single non nil context.Context argument
+func AnyContext() SingleArgMatcher {
+	return contextMatcher{desc: "AnyContext()", match: func(ctx context.Context) bool { return true }}
+}
+
+// ContextWithValue matches a single context.Context argument where ctx.Value(key) matches m
+//
+// m may be anything that can match a single argument. eg ContextWithValue(userKey, "fred")
+func ContextWithValue(key interface{}, m interface{}) SingleArgMatcher {
+	matcher := genericSingleArgumentMatcher(m)
+	return contextMatcher{
+		desc:  fmt.Sprintf("ContextWithValue(%v,%v)", key, matcher),
+		match: func(ctx context.Context) bool { return matcher.Matches(ctx.Value(key)) },
+		key:   key,
+		value: matcher,
+	}
+}
+
+/*
+ContextWithDeadlineWithin matches a single context.Context argument that has a deadline no more than d after
+the time the call is matched.
+
+An optional clock, defaulting to time.Now, can be provided. eg for use with fake clock
+*/
+func ContextWithDeadlineWithin(d time.Duration, clock ...func() time.Time) SingleArgMatcher {
+	now := time.Now
+	if len(clock) > 0 {
+		now = clock[0]
+	}
+	return contextMatcher{desc: fmt.Sprintf("ContextWithDeadlineWithin(%v)", d), match: func(ctx context.Context) bool {
+		deadline, hasDeadline := ctx.Deadline()
+		return hasDeadline && deadline.Sub(now()) <= d
+	}}
+}
+
+// ContextNotDone matches a single context.Context argument that has not been cancelled or expired
+func ContextNotDone() SingleArgMatcher {
+	return contextMatcher{desc: "ContextNotDone()", match: func(ctx context.Context) bool {
+		return ctx.Err() == nil
+	}}
+}
+
+// contextArg returns the index of the first context.Context parameter of method type mt
+func contextArg(mt reflect.Type) (int, bool) {
+	for i := 0; i < mt.NumIn(); i++ {
+		if mt.In(i).Implements(contextType) {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type ctxKey string
+
+type fetcher interface {
+	fetch(ctx context.Context, id string) error
+}
+
+type fetcherDouble struct {
+	fetcher
+	*TestDouble
+}
+
+func (d *fetcherDouble) fetch(ctx context.Context, id string) error {
+	d.TestDouble.T().Helper()
+	err, _ := d.Invoke("fetch", ctx, id)[0].(error)
+	return err
+}
+
+func TestContextMatchers(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		argType     reflect.Type
+		matching    []interface{}
+		notMatching []interface{}
+		re          string
+	}
+
+	ctxType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	background := context.Background()
+	withUser := context.WithValue(background, ctxKey("user"), "fred")
+	soon, cancelSoon := context.WithTimeout(background, time.Second)
+	defer cancelSoon()
+	later, cancelLater := context.WithTimeout(background, time.Hour)
+	defer cancelLater()
+	cancelled, cancel := context.WithCancel(background)
+	cancel()
+	var nilCtx context.Context
+
+	fakeNow := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return fakeNow }
+	fakeSoon, cancelFakeSoon := context.WithDeadline(background, fakeNow.Add(30*time.Second))
+	defer cancelFakeSoon()
+	fakeLater, cancelFakeLater := context.WithDeadline(background, fakeNow.Add(2*time.Minute))
+	defer cancelFakeLater()
+
+	tests := []test{
+		{"AnyContext", AnyContext(), ctxType, []interface{}{background, withUser}, []interface{}{nilCtx, nil, "ctx"}, `^AnyContext\(\)$`},
+		{"ContextWithValue", ContextWithValue(ctxKey("user"), "fred"), ctxType, []interface{}{withUser}, []interface{}{background, context.WithValue(background, ctxKey("user"), "bob")}, `^ContextWithValue\(user,Eql\(fred\)\)$`},
+		{"ContextWithValueMatcher", ContextWithValue(ctxKey("user"), Not(Nil())), ctxType, []interface{}{withUser}, []interface{}{background}, "ContextWithValue"},
+		{"ContextWithDeadlineWithin", ContextWithDeadlineWithin(time.Minute), ctxType, []interface{}{soon}, []interface{}{later, background}, `^ContextWithDeadlineWithin\(1m0s\)$`},
+		{"ContextWithDeadlineWithinClock", ContextWithDeadlineWithin(time.Minute, clock), ctxType, []interface{}{fakeSoon}, []interface{}{fakeLater, background}, `^ContextWithDeadlineWithin\(1m0s\)$`},
+		{"ContextNotDone", ContextNotDone(), ctxType, []interface{}{background, soon}, []interface{}{cancelled}, `^ContextNotDone\(\)$`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			matcher := test.matcher
+			if !regexp.MustCompile(test.re).MatchString(fmt.Sprint(matcher)) {
+				t.Errorf("expected '%v' to match '%s'", matcher, test.re)
+			}
+
+			matcher.ForType(t, test.argType)
+
+			for _, arg := range test.matching {
+				if !matcher.Matches(arg) {
+					t.Errorf("Expected %s to match %v", matcher, arg)
+				}
+			}
+			for _, notArg := range test.notMatching {
+				if matcher.Matches(notArg) {
+					t.Errorf("Expected %s to not match %v", matcher, notArg)
+				}
+			}
+		})
+	}
+}
+
+func TestContextMatchers_FailFatally(t *testing.T) {
+	tDouble := NewTDouble(t)
+	spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+	defer func(spy FakeMethodCall) {
+		recover()
+		spy.Matching(printfMatcher(`AnyContext\(\) cannot match type string which does not implement context.Context`)).Expect(Once())
+	}(spy)
+
+	AnyContext().ForType(tDouble, reflect.TypeOf(""))
+	t.Errorf("Expect unreachable")
+}
+
+func TestRecordedCalls_ExpectContextDone(t *testing.T) {
+	doubleT := NewTDouble(t, func(c *TestDouble) {
+		//c.EnableTrace()
+	})
+	spy := doubleT.Spy("Errorf")
+
+	d1 := &fetcherDouble{TestDouble: NewDouble(doubleT, (*fetcher)(nil), func(c *TestDouble) { c.DisableTrace() })}
+	calls := d1.Spy("fetch")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = d1.fetch(ctx, "done")
+	cancel()
+	leaked, cancelLeaked := context.WithCancel(context.Background())
+	defer cancelLeaked()
+	_ = d1.fetch(leaked, "leaked")
+
+	calls.Matching(AnyContext(), "done").ExpectContextDone()
+	spy.Expect(Never())
+
+	calls.ExpectContextDone()
+	spy.Matching(printfMatcher(`(?s)fetch\nexpected context passed at tick \d+ to be done, but it was not cancelled$`)).Expect(Once())
+}
+
+func TestRecordedCalls_ExpectContextDoneFailsFatally(t *testing.T) {
+	tDouble := NewTDouble(t)
+	spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+	defer func(spy FakeMethodCall) {
+		recover()
+		spy.Matching(printfMatcher("call has no context.Context argument")).Expect(Once())
+	}(spy)
+
+	newApiDouble(tDouble).Spy("call").ExpectContextDone()
+	t.Errorf("Expect unreachable")
+}
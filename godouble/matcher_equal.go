@@ -0,0 +1,384 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+type equalOptions struct {
+	ignoreFields     map[string]bool
+	ignoreUnexported bool
+	floatEpsilon     float64
+	equateNaNs       bool
+	sortLess         []reflect.Value
+	nilEqualsEmpty   bool
+	timeTolerance    time.Duration
+	err              error //invalid option, reported by ForType
+}
+
+// EqualOption modifies how Equal compares values
+type EqualOption struct {
+	desc  string
+	apply func(o *equalOptions)
+}
+
+func (eo EqualOption) String() string {
+	return eo.desc
+}
+
+/*
+IgnoreFields ignores struct fields by their dot separated path of field names from the compared value.
+
+Slice, array and map elements do not form part of the path. eg
+ IgnoreFields("ID", "User.CreatedAt", "Items.Price")
+*/
+func IgnoreFields(paths ...string) EqualOption {
+	return EqualOption{fmt.Sprintf("IgnoreFields(%s)", strings.Join(paths, ",")), func(o *equalOptions) {
+		for _, path := range paths {
+			o.ignoreFields[path] = true
+		}
+	}}
+}
+
+// IgnoreUnexported ignores all unexported struct fields, eg the internal state of generated protobuf messages
+func IgnoreUnexported() EqualOption {
+	return EqualOption{"IgnoreUnexported()", func(o *equalOptions) {
+		o.ignoreUnexported = true
+	}}
+}
+
+// ApproxFloats treats floats as equal if they differ by no more than epsilon
+func ApproxFloats(epsilon float64) EqualOption {
+	return EqualOption{fmt.Sprintf("ApproxFloats(%v)", epsilon), func(o *equalOptions) {
+		o.floatEpsilon = epsilon
+	}}
+}
+
+// EquateNaNs treats NaN floats as equal to each other
+func EquateNaNs() EqualOption {
+	return EqualOption{"EquateNaNs()", func(o *equalOptions) {
+		o.equateNaNs = true
+	}}
+}
+
+/*
+SortSlices sorts slices and arrays before comparing them, so that element order is ignored.
+
+less must be a func(a, b T) bool and is applied to slices with elements assignable to T. eg
+ SortSlices(func(a, b string) bool { return a < b })
+*/
+func SortSlices(less interface{}) EqualOption {
+	return EqualOption{fmt.Sprintf("SortSlices(%T)", less), func(o *equalOptions) {
+		lv := reflect.ValueOf(less)
+		lt := lv.Type()
+		if lt.Kind() != reflect.Func || lt.NumIn() != 2 || lt.In(0) != lt.In(1) || lt.NumOut() != 1 || lt.Out(0).Kind() != reflect.Bool {
+			o.err = fmt.Errorf("SortSlices expected func(a, b T) bool, got %v", lt)
+			return
+		}
+		o.sortLess = append(o.sortLess, lv)
+	}}
+}
+
+// NilEqualsEmpty treats nil slices and maps as equal to empty ones
+func NilEqualsEmpty() EqualOption {
+	return EqualOption{"NilEqualsEmpty()", func(o *equalOptions) {
+		o.nilEqualsEmpty = true
+	}}
+}
+
+// TimeWithin treats time.Time values as equal if they are no more than d apart
+//
+// time.Time values, including those in unexported fields, are always compared as instants, ignoring their location and
+// monotonic clock reading.
+func TimeWithin(d time.Duration) EqualOption {
+	return EqualOption{fmt.Sprintf("TimeWithin(%v)", d), func(o *equalOptions) {
+		o.timeTolerance = d
+	}}
+}
+
+// Constants of the time package's encoding of a time.Time in its wall and ext fields
+const (
+	timeHasMonotonic   = 1 << 63
+	timeNsecMask       = 1<<30 - 1
+	timeNsecShift      = 30
+	timeWallToInternal = (1884*365 + 1884/4 - 1884/100 + 1884/400) * 24 * 60 * 60
+	timeUnixToInternal = (1969*365 + 1969/4 - 1969/100 + 1969/400) * 24 * 60 * 60
+)
+
+// timeOf is the time.Time in v.
+//
+// A time.Time from an unexported field cannot be Interface()d, so its instant is read from the wall and ext fields.
+func timeOf(v reflect.Value) (time.Time, bool) {
+	if v.CanInterface() {
+		return v.Interface().(time.Time), true
+	}
+	wallField, extField := v.FieldByName("wall"), v.FieldByName("ext")
+	if wallField.Kind() != reflect.Uint64 || extField.Kind() != reflect.Int64 {
+		return time.Time{}, false
+	}
+	wall, ext := wallField.Uint(), extField.Int()
+	sec := ext
+	if wall&timeHasMonotonic != 0 {
+		sec = timeWallToInternal + int64(wall<<1>>(timeNsecShift+1))
+	}
+	return time.Unix(sec-timeUnixToInternal, int64(wall&timeNsecMask)), true
+}
+
+// visit is a pair of pointers already being compared, to avoid infinite recursion on cyclic values
+type visit struct {
+	expected uintptr
+	actual   uintptr
+	typ      reflect.Type
+}
+
+type equalComparison struct {
+	*equalOptions
+	visited map[visit]bool
+	diffs   []string
+}
+
+func (c *equalComparison) differ(path string, format string, args ...interface{}) {
+	if path != "" {
+		format = path + ": " + format
+	}
+	c.diffs = append(c.diffs, fmt.Sprintf(format, args...))
+}
+
+func formatValueOf(v reflect.Value) string {
+	sb := &strings.Builder{}
+	formatValue(sb, v, 0)
+	return sb.String()
+}
+
+// compare records the differences between expected and actual at path.
+//
+// fields is the path of struct field names only, used to match IgnoreFields
+func (c *equalComparison) compare(path string, fields string, expected reflect.Value, actual reflect.Value) {
+	if !expected.IsValid() || !actual.IsValid() {
+		if expected.IsValid() != actual.IsValid() {
+			c.differ(path, "expected %s, found %s", formatValueOf(expected), formatValueOf(actual))
+		}
+		return
+	}
+	if expected.Type() != actual.Type() {
+		c.differ(path, "expected type %v, found type %v", expected.Type(), actual.Type())
+		return
+	}
+
+	if expected.Type() == timeType {
+		et, expectedOK := timeOf(expected)
+		at, actualOK := timeOf(actual)
+		if expectedOK && actualOK {
+			if diff := at.Sub(et); diff > c.timeTolerance || -diff > c.timeTolerance {
+				c.differ(path, "expected %s, found %s", formatValueOf(expected), formatValueOf(actual))
+			}
+			return
+		}
+	}
+
+	switch expected.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if expected.IsNil() || actual.IsNil() {
+			if expected.IsNil() != actual.IsNil() {
+				c.differ(path, "expected %s, found %s", formatValueOf(expected), formatValueOf(actual))
+			}
+			return
+		}
+		if expected.Kind() == reflect.Ptr {
+			v := visit{expected.Pointer(), actual.Pointer(), expected.Type()}
+			if c.visited[v] {
+				return
+			}
+			c.visited[v] = true
+		}
+		c.compare(path, fields, expected.Elem(), actual.Elem())
+	case reflect.Struct:
+		for i := 0; i < expected.NumField(); i++ {
+			field := expected.Type().Field(i)
+			fieldPath := field.Name
+			if fields != "" {
+				fieldPath = fields + "." + field.Name
+			}
+			if c.ignoreFields[fieldPath] || (c.ignoreUnexported && field.PkgPath != "") {
+				continue
+			}
+			displayPath := field.Name
+			if path != "" {
+				displayPath = path + "." + field.Name
+			}
+			c.compare(displayPath, fieldPath, expected.Field(i), actual.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		if expected.Kind() == reflect.Slice && (expected.IsNil() || actual.IsNil()) && !c.nilEqualsEmpty {
+			if expected.IsNil() != actual.IsNil() {
+				c.differ(path, "expected %s, found %s", formatValueOf(expected), formatValueOf(actual))
+			}
+			return
+		}
+		if expected.Len() != actual.Len() {
+			c.differ(path, "expected %d elements, found %d", expected.Len(), actual.Len())
+			return
+		}
+		expected, actual = c.sorted(expected), c.sorted(actual)
+		for i := 0; i < expected.Len(); i++ {
+			c.compare(fmt.Sprintf("%s[%d]", path, i), fields, expected.Index(i), actual.Index(i))
+		}
+	case reflect.Map:
+		if (expected.IsNil() || actual.IsNil()) && !c.nilEqualsEmpty {
+			if expected.IsNil() != actual.IsNil() {
+				c.differ(path, "expected %s, found %s", formatValueOf(expected), formatValueOf(actual))
+			}
+			return
+		}
+		keys := append(expected.MapKeys(), actual.MapKeys()...)
+		sort.Slice(keys, func(i, j int) bool { return formatValueOf(keys[i]) < formatValueOf(keys[j]) })
+		for i, k := range keys {
+			if i > 0 && formatValueOf(k) == formatValueOf(keys[i-1]) {
+				continue
+			}
+			keyPath := fmt.Sprintf("%s[%s]", path, formatValueOf(k))
+			ev, av := expected.MapIndex(k), actual.MapIndex(k)
+			switch {
+			case !av.IsValid():
+				c.differ(keyPath, "missing, expected %s", formatValueOf(ev))
+			case !ev.IsValid():
+				c.differ(keyPath, "unexpected %s", formatValueOf(av))
+			default:
+				c.compare(keyPath, fields, ev, av)
+			}
+		}
+	case reflect.Float32, reflect.Float64:
+		e, a := expected.Float(), actual.Float()
+		switch {
+		case math.IsNaN(e) || math.IsNaN(a):
+			if !(c.equateNaNs && math.IsNaN(e) && math.IsNaN(a)) {
+				c.differ(path, "expected %s, found %s", formatValueOf(expected), formatValueOf(actual))
+			}
+		case math.Abs(e-a) > c.floatEpsilon:
+			c.differ(path, "expected %s, found %s", formatValueOf(expected), formatValueOf(actual))
+		}
+	case reflect.Bool:
+		c.compareScalar(path, expected, actual, expected.Bool() == actual.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		c.compareScalar(path, expected, actual, expected.Int() == actual.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		c.compareScalar(path, expected, actual, expected.Uint() == actual.Uint())
+	case reflect.Complex64, reflect.Complex128:
+		c.compareScalar(path, expected, actual, expected.Complex() == actual.Complex())
+	case reflect.String:
+		c.compareScalar(path, expected, actual, expected.String() == actual.String())
+	case reflect.Func:
+		//as per reflect.DeepEqual, funcs are only equal if both are nil
+		c.compareScalar(path, expected, actual, expected.IsNil() && actual.IsNil())
+	default:
+		//Chan, UnsafePointer
+		c.compareScalar(path, expected, actual, expected.Pointer() == actual.Pointer())
+	}
+}
+
+func (c *equalComparison) compareScalar(path string, expected reflect.Value, actual reflect.Value, equal bool) {
+	if !equal {
+		c.differ(path, "expected %s, found %s", formatValueOf(expected), formatValueOf(actual))
+	}
+}
+
+// sorted returns a sorted copy of the slice or array v if there is a SortSlices option for its element type
+func (c *equalComparison) sorted(v reflect.Value) reflect.Value {
+	for _, less := range c.sortLess {
+		if !v.Type().Elem().AssignableTo(less.Type().In(0)) || !v.CanInterface() {
+			continue
+		}
+		sortedV := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), v.Len(), v.Len())
+		reflect.Copy(sortedV, v)
+		sort.SliceStable(sortedV.Interface(), func(i, j int) bool {
+			return less.Call([]reflect.Value{sortedV.Index(i), sortedV.Index(j)})[0].Bool()
+		})
+		return sortedV
+	}
+	return v
+}
+
+type equalMatcher struct {
+	expected interface{}
+	options  []EqualOption
+	*equalOptions
+}
+
+func (em equalMatcher) String() string {
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "Equal(%v", em.expected)
+	for _, option := range em.options {
+		fmt.Fprintf(&sb, ",%v", option)
+	}
+	sb.WriteRune(')')
+	return sb.String()
+}
+
+func (em equalMatcher) diff(arg interface{}) []string {
+	c := &equalComparison{equalOptions: em.equalOptions, visited: make(map[visit]bool)}
+	c.compare("", "", reflect.ValueOf(em.expected), reflect.ValueOf(arg))
+	return c.diffs
+}
+
+func (em equalMatcher) Matches(args ...interface{}) bool {
+	return em.err == nil && len(em.diff(args[0])) == 0
+}
+
+// Explain lists the differences, identified by path from the argument
+func (em equalMatcher) Explain(args ...interface{}) (bool, string) {
+	if em.err != nil {
+		return false, fmt.Sprintf("%v is invalid: %s", em, em.err.Error())
+	}
+	diffs := em.diff(args[0])
+	return len(diffs) == 0, strings.Join(diffs, "\n")
+}
+
+func (em equalMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if em.err != nil {
+		t.Fatalf("%v is invalid: %s", em, em.err.Error())
+	}
+	if et := reflect.TypeOf(em.expected); et != nil && !et.AssignableTo(ft) {
+		t.Fatalf("%v cannot match type %v", em, ft)
+	}
+}
+
+/*
+Equal matches a single argument that is deeply equal to expected, as per reflect.DeepEqual except that
+time.Time values are compared with time.Time.Equal (ignoring monotonic clock readings and location).
+
+Options relax the comparison, eg
+ Equal(expectedUser, IgnoreFields("ID", "CreatedAt"), IgnoreUnexported())
+ Equal(expectedPoints, ApproxFloats(1e-9), SortSlices(func(a, b Point) bool { return a.X < b.X }))
+
+When a call does not match, the explanation lists each difference by path. eg
+ User.Name: expected "fred", found "frank"
+ Items: expected 2 elements, found 3
+*/
+func Equal(expected interface{}, opts ...EqualOption) SingleArgMatcher {
+	options := &equalOptions{ignoreFields: make(map[string]bool)}
+	for _, opt := range opts {
+		opt.apply(options)
+	}
+	return equalMatcher{expected, opts, options}
+}
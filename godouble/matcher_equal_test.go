@@ -0,0 +1,197 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type testAccount struct {
+	ID      int
+	Name    string
+	Tags    []string
+	Limits  map[string]float64
+	Created time.Time
+	Owner   *testUser
+	cache   string
+}
+
+func TestEqual(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		argType     reflect.Type
+		matching    []interface{}
+		notMatching []interface{}
+		re          string
+	}
+
+	accountType := reflect.TypeOf(testAccount{})
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	account := testAccount{ID: 1, Name: "fred", Tags: []string{"a", "b"}, Limits: map[string]float64{"x": 1.5}, Created: created, Owner: &testUser{ID: 42}}
+	with := func(modify func(a *testAccount)) testAccount {
+		modified := account
+		modify(&modified)
+		return modified
+	}
+
+	tests := []test{
+		{"Equal", Equal(account), accountType,
+			[]interface{}{with(func(a *testAccount) { a.Owner = &testUser{ID: 42} }), with(func(a *testAccount) { a.Created = created.In(time.Local) })},
+			[]interface{}{with(func(a *testAccount) { a.cache = "x" }), with(func(a *testAccount) { a.Tags = []string{"b", "a"} }), with(func(a *testAccount) { a.Owner = nil })},
+			`^Equal\(\{1 fred`},
+		{"IgnoreFields", Equal(account, IgnoreFields("ID", "Owner.ID")), accountType,
+			[]interface{}{with(func(a *testAccount) { a.ID = 2; a.Owner = &testUser{ID: 43} })},
+			[]interface{}{with(func(a *testAccount) { a.Owner = nil })},
+			`IgnoreFields\(ID,Owner.ID\)\)$`},
+		{"IgnoreUnexported", Equal(account, IgnoreUnexported()), accountType, []interface{}{with(func(a *testAccount) { a.cache = "x" })}, []interface{}{with(func(a *testAccount) { a.ID = 2 })}, `IgnoreUnexported\(\)`},
+		{"ApproxFloats", Equal(account, ApproxFloats(0.01)), accountType, []interface{}{with(func(a *testAccount) { a.Limits = map[string]float64{"x": 1.505} })}, []interface{}{with(func(a *testAccount) { a.Limits = map[string]float64{"x": 1.6} })}, `ApproxFloats\(0.01\)`},
+		{"NaN", Equal(math.NaN()), reflect.TypeOf(0.0), []interface{}{}, []interface{}{math.NaN(), 1.0}, `NaN`},
+		{"EquateNaNs", Equal([]float64{math.NaN()}, EquateNaNs()), reflect.TypeOf([]float64{}), []interface{}{[]float64{math.NaN()}}, []interface{}{[]float64{1}}, `EquateNaNs\(\)`},
+		{"SortSlices", Equal(account, SortSlices(func(a, b string) bool { return a < b })), accountType, []interface{}{with(func(a *testAccount) { a.Tags = []string{"b", "a"} })}, []interface{}{with(func(a *testAccount) { a.Tags = []string{"b", "c"} })}, `SortSlices\(func\(string, string\) bool\)`},
+		{"NilSlice", Equal([]int{}), reflect.TypeOf([]int{}), []interface{}{[]int{}}, []interface{}{[]int(nil)}, `Equal`},
+		{"NilEqualsEmpty", Equal(with(func(a *testAccount) { a.Tags = nil }), NilEqualsEmpty()), accountType, []interface{}{with(func(a *testAccount) { a.Tags = []string{} })}, []interface{}{account}, `NilEqualsEmpty\(\)`},
+		{"NilEqualsEmptyNil", Equal(map[string]int{}, NilEqualsEmpty()), reflect.TypeOf(map[string]int{}), []interface{}{map[string]int(nil)}, []interface{}{map[string]int{"a": 1}}, `NilEqualsEmpty\(\)`},
+		{"TimeWithin", Equal(created, TimeWithin(time.Second)), reflect.TypeOf(created), []interface{}{created.Add(time.Second), created.Add(-time.Millisecond)}, []interface{}{created.Add(2 * time.Second)}, `TimeWithin\(1s\)`},
+		{"Interface", Equal(42), reflect.TypeOf((*interface{})(nil)).Elem(), []interface{}{42}, []interface{}{int64(42), nil}, `^Equal\(42\)$`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			matcher := test.matcher
+			if !regexp.MustCompile(test.re).MatchString(fmt.Sprint(matcher)) {
+				t.Errorf("expected '%v' to match '%s'", matcher, test.re)
+			}
+
+			matcher.ForType(t, test.argType)
+
+			for _, arg := range test.matching {
+				if !matcher.Matches(arg) {
+					_, explanation := Explain(matcher, arg)
+					t.Errorf("Expected %s to match %v\n%s", matcher, arg, explanation)
+				}
+			}
+			for _, notArg := range test.notMatching {
+				if matcher.Matches(notArg) {
+					t.Errorf("Expected %s to not match %v", matcher, notArg)
+				}
+			}
+		})
+	}
+}
+
+func TestEqual_UnexportedTimes(t *testing.T) {
+	type timed struct {
+		at time.Time
+	}
+
+	now := time.Now() //has a monotonic clock reading
+	for _, test := range []struct {
+		name     string
+		matcher  SingleArgMatcher
+		matching timed
+		notMatch timed
+	}{
+		{"Monotonic", Equal(timed{now}), timed{now.Round(0)}, timed{now.Add(time.Nanosecond)}},
+		{"Location", Equal(timed{now.UTC()}), timed{now.In(time.FixedZone("X", 3600))}, timed{now.Add(time.Second)}},
+		{"Distant", Equal(timed{time.Date(1066, 10, 14, 9, 0, 0, 1, time.UTC)}), timed{time.Date(1066, 10, 14, 10, 0, 0, 1, time.FixedZone("X", 3600))}, timed{time.Date(1066, 10, 14, 9, 0, 0, 2, time.UTC)}},
+		{"TimeWithin", Equal(timed{now}, TimeWithin(time.Second)), timed{now.Add(-time.Second)}, timed{now.Add(2 * time.Second)}},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.matcher.ForType(t, reflect.TypeOf(timed{}))
+			if matched, explanation := Explain(test.matcher, test.matching); !matched {
+				t.Errorf("Expected %v to match %v: %s", test.matcher, test.matching, explanation)
+			}
+			if test.matcher.Matches(test.notMatch) {
+				t.Errorf("Expected %v to not match %v", test.matcher, test.notMatch)
+			}
+		})
+	}
+}
+
+func TestEqual_Explain(t *testing.T) {
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	expected := testAccount{ID: 1, Name: "fred", Tags: []string{"a"}, Limits: map[string]float64{"x": 1, "y": 2}, Created: created, Owner: &testUser{ID: 42}}
+	actual := testAccount{ID: 1, Name: "frank", Tags: []string{"a", "b"}, Limits: map[string]float64{"x": 1.5, "z": 3}, Created: created.Add(time.Hour), Owner: &testUser{ID: 43}, cache: "c"}
+
+	_, explanation := Explain(Equal(expected), actual)
+	expectedExplanation := `Name: expected "fred", found "frank"` +
+		"\nTags: expected 1 elements, found 2" +
+		"\nLimits[\"x\"]: expected 1, found 1.5" +
+		"\nLimits[\"y\"]: missing, expected 2" +
+		"\nLimits[\"z\"]: unexpected 3" +
+		"\nCreated: expected 2020-01-02T03:04:05Z, found 2020-01-02T04:04:05Z" +
+		"\nOwner.ID: expected 42, found 43" +
+		"\ncache: expected \"\", found \"c\""
+	if explanation != expectedExplanation {
+		t.Errorf("Expected explanation\n%s\ngot\n%s", expectedExplanation, explanation)
+	}
+
+	if _, explanation := Explain(Equal(1), 2); explanation != "expected 1, found 2" {
+		t.Errorf("Expected root explanation, got %q", explanation)
+	}
+	if _, explanation := Explain(Equal(1), int64(1)); explanation != "expected type int, found type int64" {
+		t.Errorf("Expected type explanation, got %q", explanation)
+	}
+}
+
+func TestEqual_Cyclic(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	a, b := &node{Name: "a"}, &node{Name: "a"}
+	a.Next, b.Next = a, b
+	if !Equal(a).Matches(b) {
+		t.Errorf("Expected cyclic values to be equal")
+	}
+}
+
+func TestEqual_FailsFatally(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		failType    reflect.Type
+		expectedMsg string
+	}
+
+	tests := []test{
+		{"WrongType", Equal(1), reflect.TypeOf(""), `Equal\(1\) cannot match type string`},
+		{"BadSortSlices", Equal([]int{}, SortSlices(func(a int) bool { return true })), reflect.TypeOf([]int{}), `SortSlices expected func\(a, b T\) bool, got func\(int\) bool`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			test.matcher.ForType(tDouble, test.failType)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
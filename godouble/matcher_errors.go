@@ -0,0 +1,163 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// errorMatcher matches a single non nil error argument
+type errorMatcher struct {
+	desc  string
+	match func(err error) bool
+	check func(t T) //optional additional setup validation
+}
+
+func (em errorMatcher) String() string {
+	return em.desc
+}
+
+func (em errorMatcher) Matches(args ...interface{}) bool {
+	if err, isError := args[0].(error); isError && err != nil {
+		return em.match(err)
+	}
+	return false
+}
+
+func (em errorMatcher) Explain(args ...interface{}) (bool, string) {
+	return mismatch(em, args)
+}
+
+func (em errorMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if !ft.Implements(errorType) && !(ft.Kind() == reflect.Interface && ft.NumMethod() == 0) {
+		t.Fatalf("%v cannot match type %v which does not implement error", em, ft)
+	}
+	if em.check != nil {
+		em.check(t)
+	}
+}
+
+// AnyError matches a single non nil error argument
+func AnyError() SingleArgMatcher {
+	return errorMatcher{desc: "AnyError()", match: func(err error) bool { return true }}
+}
+
+// ErrorIs matches a single error argument where errors.Is(arg, target)
+//
+// eg spy.Matching(ErrorIs(io.EOF)).Expect(Once())
+func ErrorIs(target error) SingleArgMatcher {
+	return errorMatcher{desc: fmt.Sprintf("ErrorIs(%v)", target), match: func(err error) bool {
+		return errors.Is(err, target)
+	}}
+}
+
+// ErrorMessage matches a single non nil error argument whose Error() string matches m
+//
+// m may be anything that can match a string. eg ErrorMessage("not found"), ErrorMessage(HasPrefix("timeout"))
+func ErrorMessage(m interface{}) SingleArgMatcher {
+	matcher := genericSingleArgumentMatcher(m)
+	return errorMatcher{
+		desc:  fmt.Sprintf("ErrorMessage(%v)", matcher),
+		match: func(err error) bool { return matcher.Matches(err.Error()) },
+		check: func(t T) {
+			t.Helper()
+			matcher.ForType(t, reflect.TypeOf(""))
+		},
+	}
+}
+
+// asType determines the type errors.As should look for given an example target
+//
+// A pointer to an interface or error type is checked first, because *E also implements error when E does.
+func asType(target interface{}) (reflect.Type, bool) {
+	tt := reflect.TypeOf(target)
+	switch {
+	case tt == nil:
+		return nil, false
+	case tt.Kind() == reflect.Ptr && (tt.Elem().Kind() == reflect.Interface || tt.Elem().Implements(errorType)):
+		return tt.Elem(), true
+	case tt.Implements(errorType):
+		return tt, true
+	default:
+		return nil, false
+	}
+}
+
+type errorAsMatcher struct {
+	target      interface{}
+	as          reflect.Type //nil if target is invalid
+	matcher     SingleArgMatcher
+	hasMatchers bool
+}
+
+func (em errorAsMatcher) String() string {
+	if em.hasMatchers {
+		return fmt.Sprintf("ErrorAs(%T,%v)", em.target, em.matcher)
+	}
+	return fmt.Sprintf("ErrorAs(%T)", em.target)
+}
+
+func (em errorAsMatcher) Matches(args ...interface{}) bool {
+	err, isError := args[0].(error)
+	if !isError || err == nil || em.as == nil {
+		return false
+	}
+	found := reflect.New(em.as)
+	return errors.As(err, found.Interface()) && em.matcher.Matches(found.Elem().Interface())
+}
+
+func (em errorAsMatcher) Explain(args ...interface{}) (bool, string) {
+	err, isError := args[0].(error)
+	if !isError || err == nil || em.as == nil {
+		return mismatch(em, args)
+	}
+	found := reflect.New(em.as)
+	if !errors.As(err, found.Interface()) {
+		return false, fmt.Sprintf("%s has no %v in its chain", formatArg(err), em.as)
+	}
+	if matched, explanation := Explain(em.matcher, found.Elem().Interface()); !matched {
+		return false, fmt.Sprintf("%v: %s", em.as, indent(explanation, "  "))
+	}
+	return true, ""
+}
+
+func (em errorAsMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	errorMatcher{desc: em.String()}.ForType(t, ft)
+	if em.as == nil {
+		t.Fatalf("%v target must implement error or be a pointer to an interface or error type", em)
+	}
+	em.matcher.ForType(t, em.as)
+}
+
+/*
+ErrorAs matches a single error argument where errors.As finds an error of the type of target in the chain
+
+target is typically a nil pointer of the required type. eg ErrorAs((*MyErr)(nil)) finds a MyErr if MyErr implements
+error, otherwise a *MyErr. Use ErrorAs((**MyErr)(nil)) to find a *MyErr where MyErr implements error with a
+value receiver. A pointer to an interface type is also accepted. eg ErrorAs((*net.Error)(nil))
+
+Optional matchers, each anything that can match a single argument, are applied to the error found. eg
+ ErrorAs((*os.PathError)(nil), Field("Op", "open"))
+*/
+func ErrorAs(target interface{}, matchers ...interface{}) SingleArgMatcher {
+	as, _ := asType(target)
+	return errorAsMatcher{target: target, as: as, matcher: andMatcher{newCombinationMatcher(toMatcherList(matchers), "All")}, hasMatchers: len(matchers) > 0}
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// valueErr implements error with a value receiver
+type valueErr struct {
+	Code int
+}
+
+func (e valueErr) Error() string {
+	return fmt.Sprintf("code %d", e.Code)
+}
+
+// ptrErr implements error with a pointer receiver
+type ptrErr struct {
+	Op string
+}
+
+func (e *ptrErr) Error() string {
+	return e.Op + " failed"
+}
+
+type reporter interface {
+	report(err error)
+}
+
+type reporterDouble struct {
+	reporter
+	*TestDouble
+}
+
+func newReporterDouble(t T, configurators ...func(*TestDouble)) *reporterDouble {
+	return &reporterDouble{TestDouble: NewDouble(t, (*reporter)(nil), configurators...)}
+}
+
+func (d *reporterDouble) report(err error) {
+	d.TestDouble.T().Helper()
+	d.Invoke("report", err)
+}
+
+func TestErrorMatchers(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		matching    []interface{}
+		notMatching []interface{}
+		re          string
+	}
+
+	var nilErr error
+	notFound := errors.New("not found")
+	wrappedEOF := fmt.Errorf("reading: %w", io.EOF)
+
+	tests := []test{
+		{"AnyError", AnyError(), []interface{}{notFound, io.EOF}, []interface{}{nilErr, nil, "error"}, `^AnyError\(\)$`},
+		{"ErrorIs", ErrorIs(io.EOF), []interface{}{io.EOF, wrappedEOF}, []interface{}{errors.New("EOF"), nilErr}, `^ErrorIs\(EOF\)$`},
+		{"ErrorMessage", ErrorMessage("not found"), []interface{}{notFound, errors.New("not found")}, []interface{}{io.EOF, nilErr}, `^ErrorMessage\(Eql\(not found\)\)$`},
+		{"ErrorMessageMatcher", ErrorMessage(HasPrefix("timeout")), []interface{}{errors.New("timeout after 1s")}, []interface{}{errors.New("no timeout")}, `^ErrorMessage\(HasPrefix`},
+		{"ErrorAsValueReceiver", ErrorAs((*valueErr)(nil)), []interface{}{valueErr{1}, fmt.Errorf("wrapped: %w", valueErr{2})}, []interface{}{&valueErr{3}, notFound, nilErr}, `^ErrorAs\(\*godouble.valueErr\)$`},
+		{"ErrorAsPointerToValueReceiver", ErrorAs((**valueErr)(nil)), []interface{}{&valueErr{3}}, []interface{}{valueErr{1}}, `^ErrorAs\(\*\*godouble.valueErr\)$`},
+		{"ErrorAsPointerReceiver", ErrorAs((*ptrErr)(nil)), []interface{}{&ptrErr{"open"}, fmt.Errorf("wrapped: %w", &ptrErr{"close"})}, []interface{}{valueErr{1}, nilErr}, `^ErrorAs\(\*godouble.ptrErr\)$`},
+		{"ErrorAsValue", ErrorAs(valueErr{}), []interface{}{valueErr{1}}, []interface{}{&ptrErr{"open"}}, `^ErrorAs\(godouble.valueErr\)$`},
+		{"ErrorAsInterface", ErrorAs((*net.Error)(nil)), []interface{}{&net.DNSError{IsTimeout: true}}, []interface{}{notFound}, `^ErrorAs\(\*net.Error\)$`},
+		{"ErrorAsWithMatchers", ErrorAs((*ptrErr)(nil), Field("Op", "open")), []interface{}{&ptrErr{"open"}}, []interface{}{&ptrErr{"close"}, valueErr{1}}, `^ErrorAs\(\*godouble.ptrErr,.*Op`},
+		{"ErrorAsValueWithMatchers", ErrorAs((*valueErr)(nil), Field("Code", 2)), []interface{}{fmt.Errorf("wrapped: %w", valueErr{2})}, []interface{}{valueErr{1}}, `Code`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			matcher := test.matcher
+			if !regexp.MustCompile(test.re).MatchString(fmt.Sprint(matcher)) {
+				t.Errorf("expected '%v' to match '%s'", matcher, test.re)
+			}
+
+			matcher.ForType(t, errorType)
+
+			for _, arg := range test.matching {
+				if !matcher.Matches(arg) {
+					t.Errorf("Expected %s to match %v", matcher, arg)
+				}
+			}
+			for _, notArg := range test.notMatching {
+				if matcher.Matches(notArg) {
+					t.Errorf("Expected %s to not match %v", matcher, notArg)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorMatchers_Explain(t *testing.T) {
+	if matched, explanation := Explain(ErrorAs((*ptrErr)(nil)), valueErr{1}); matched || !regexp.MustCompile(`no \*godouble.ptrErr in its chain`).MatchString(explanation) {
+		t.Errorf("Expected explanation of missing error type, got %v, %s", matched, explanation)
+	}
+	if matched, explanation := Explain(ErrorAs((*ptrErr)(nil), Field("Op", "open")), &ptrErr{"close"}); matched || !regexp.MustCompile(`^\*godouble.ptrErr: `).MatchString(explanation) {
+		t.Errorf("Expected explanation of field mismatch, got %v, %s", matched, explanation)
+	}
+}
+
+func TestErrorMatchers_FailFatally(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		argType     reflect.Type
+		expectedMsg string
+	}
+
+	tests := []test{
+		{"NotError", AnyError(), reflect.TypeOf(""), `AnyError\(\) cannot match type string which does not implement error`},
+		{"ErrorMessageMatcher", ErrorMessage(Field("Missing", 1)), errorType, `Missing`},
+		{"ErrorAsTarget", ErrorAs("not an error"), errorType, `target must implement error`},
+		{"ErrorAsMatcher", ErrorAs((*ptrErr)(nil), Field("Missing", 1)), errorType, `Missing`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			test.matcher.ForType(tDouble, test.argType)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
+
+func TestErrorMatchers_MethodArgs(t *testing.T) {
+	d := newReporterDouble(t)
+	spy := d.Spy("report")
+	d.report(nil)
+	d.report(fmt.Errorf("reading: %w", io.EOF))
+	d.report(&ptrErr{"open"})
+
+	spy.Matching(ErrorIs(io.EOF)).Expect(Once())
+	spy.Matching(ErrorAs((*ptrErr)(nil))).Expect(Once())
+	spy.Matching(AnyError()).Expect(Twice())
+	spy.Matching(Nil()).Expect(Once())
+}
@@ -0,0 +1,357 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// decodeJSON decodes a string, []byte (eg json.RawMessage) or fmt.Stringer argument into generic JSON values
+func decodeJSON(arg interface{}) (interface{}, error) {
+	s, isString := stringOf(arg)
+	if !isString {
+		return nil, fmt.Errorf("%T is not a JSON string or []byte", arg)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	return decoded, nil
+}
+
+// normalizeJSON converts an arbitrary go value to generic JSON values by marshalling and unmarshalling it
+func normalizeJSON(v interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	err = json.Unmarshal(encoded, &decoded)
+	return decoded, err
+}
+
+func compactJSON(v interface{}) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
+
+// diffJSON returns the structural differences between generic JSON values, identified by path
+func diffJSON(path string, expected interface{}, actual interface{}) []string {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, isObject := actual.(map[string]interface{})
+		if !isObject {
+			break
+		}
+		keys := make([]string, 0, len(e)+len(a))
+		for k := range e {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, inExpected := e[k]; !inExpected {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		var diffs []string
+		for _, k := range keys {
+			ev, inExpected := e[k]
+			av, inActual := a[k]
+			keyPath := path + "." + k
+			switch {
+			case !inActual:
+				diffs = append(diffs, fmt.Sprintf("%s: missing, expected %s", keyPath, compactJSON(ev)))
+			case !inExpected:
+				diffs = append(diffs, fmt.Sprintf("%s: unexpected %s", keyPath, compactJSON(av)))
+			default:
+				diffs = append(diffs, diffJSON(keyPath, ev, av)...)
+			}
+		}
+		return diffs
+	case []interface{}:
+		a, isArray := actual.([]interface{})
+		if !isArray {
+			break
+		}
+		var diffs []string
+		if len(e) != len(a) {
+			diffs = append(diffs, fmt.Sprintf("%s: expected %d elements, found %d", path, len(e), len(a)))
+		}
+		for i := 0; i < len(e) && i < len(a); i++ {
+			diffs = append(diffs, diffJSON(fmt.Sprintf("%s[%d]", path, i), e[i], a[i])...)
+		}
+		return diffs
+	default:
+		if reflect.DeepEqual(expected, actual) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("%s: expected %s, found %s", path, compactJSON(expected), compactJSON(actual))}
+}
+
+type jsonEqMatcher struct {
+	expected interface{}
+	desc     string
+	err      error //invalid expected value, reported by ForType
+}
+
+func (jm jsonEqMatcher) String() string {
+	return jm.desc
+}
+
+func (jm jsonEqMatcher) Matches(args ...interface{}) bool {
+	if jm.err != nil {
+		return false
+	}
+	actual, err := decodeJSON(args[0])
+	return err == nil && reflect.DeepEqual(jm.expected, actual)
+}
+
+func (jm jsonEqMatcher) Explain(args ...interface{}) (bool, string) {
+	if jm.err != nil {
+		return false, fmt.Sprintf("%v is invalid: %s", jm, jm.err.Error())
+	}
+	actual, err := decodeJSON(args[0])
+	if err != nil {
+		return false, err.Error()
+	}
+	diffs := diffJSON("$", jm.expected, actual)
+	return len(diffs) == 0, strings.Join(diffs, "\n")
+}
+
+func (jm jsonEqMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if jm.err != nil {
+		t.Fatalf("%v is invalid: %s", jm, jm.err.Error())
+	}
+	if !stringable(ft) {
+		t.Fatalf("%v cannot match type %v which is not a string, []byte or fmt.Stringer", jm, ft)
+	}
+}
+
+/*
+JSONEq matches a single string, []byte (eg json.RawMessage) or fmt.Stringer argument containing JSON
+that is semantically equal to expected, ignoring key order and whitespace.
+
+expected may be a JSON string, []byte or json.RawMessage, otherwise it is marshalled to JSON. eg
+ JSONEq(`{"user": {"id": 42}}`)
+ JSONEq(map[string]interface{}{"user": map[string]int{"id": 42}})
+
+An invalid expected document will fatally fail the test when the matcher is used.
+*/
+func JSONEq(expected interface{}) SingleArgMatcher {
+	var decoded interface{}
+	var err error
+	if s, isString := expected.(string); isString {
+		decoded, err = decodeJSON(s)
+	} else if b, isBytes := expected.([]byte); isBytes {
+		decoded, err = decodeJSON(b)
+	} else if raw, isRaw := expected.(json.RawMessage); isRaw {
+		decoded, err = decodeJSON([]byte(raw))
+	} else {
+		decoded, err = normalizeJSON(expected)
+	}
+	return jsonEqMatcher{expected: decoded, desc: fmt.Sprintf("JSONEq(%s)", compactJSON(decoded)), err: err}
+}
+
+// jsonStep is one step in a JSONPath, either an object key or an array index
+type jsonStep struct {
+	key   string
+	index int //-1 for key steps
+}
+
+func parseJSONPath(path string) ([]jsonStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+	var steps []jsonStep
+	rest := path[1:]
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in path %q", path)
+			}
+			steps = append(steps, jsonStep{key: key, index: -1})
+			rest = rest[end+1:]
+		case '[':
+			end := strings.IndexRune(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [ in path %q", path)
+			}
+			selector := rest[1:end]
+			if unquoted, err := strconv.Unquote(strings.Replace(selector, "'", `"`, -1)); err == nil && len(selector) > 1 {
+				steps = append(steps, jsonStep{key: unquoted, index: -1})
+			} else if index, err := strconv.Atoi(selector); err == nil && index >= 0 {
+				steps = append(steps, jsonStep{index: index})
+			} else {
+				return nil, fmt.Errorf("invalid selector [%s] in path %q", selector, path)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected %q in path %q", rest[0], path)
+		}
+	}
+	return steps, nil
+}
+
+type jsonPathMatcher struct {
+	path    string
+	steps   []jsonStep
+	matcher SingleArgMatcher
+	err     error //invalid path, reported by ForType
+}
+
+func (jm jsonPathMatcher) String() string {
+	return fmt.Sprintf("JSONPath(%s,%v)", jm.path, jm.matcher)
+}
+
+// resolve finds the value at the path, reason is non empty if it cannot be found
+func (jm jsonPathMatcher) resolve(arg interface{}) (value interface{}, reason string) {
+	value, err := decodeJSON(arg)
+	if err != nil {
+		return nil, err.Error()
+	}
+	at := "$"
+	for _, step := range jm.steps {
+		if step.index < 0 {
+			object, isObject := value.(map[string]interface{})
+			if !isObject {
+				return nil, fmt.Sprintf("%s: %s is not an object", at, compactJSON(value))
+			}
+			var found bool
+			if value, found = object[step.key]; !found {
+				return nil, fmt.Sprintf("%s: has no key %q", at, step.key)
+			}
+			at += "." + step.key
+		} else {
+			array, isArray := value.([]interface{})
+			if !isArray {
+				return nil, fmt.Sprintf("%s: %s is not an array", at, compactJSON(value))
+			}
+			if step.index >= len(array) {
+				return nil, fmt.Sprintf("%s: has no element [%d] in %d elements", at, step.index, len(array))
+			}
+			value = array[step.index]
+			at += fmt.Sprintf("[%d]", step.index)
+		}
+	}
+	return value, ""
+}
+
+func (jm jsonPathMatcher) Matches(args ...interface{}) bool {
+	if jm.err != nil {
+		return false
+	}
+	value, reason := jm.resolve(args[0])
+	return reason == "" && jm.matcher.Matches(value)
+}
+
+func (jm jsonPathMatcher) Explain(args ...interface{}) (bool, string) {
+	if jm.err != nil {
+		return false, fmt.Sprintf("%v is invalid: %s", jm, jm.err.Error())
+	}
+	value, reason := jm.resolve(args[0])
+	if reason != "" {
+		return false, reason
+	}
+	if expected, isValue := jm.matcher.(jsonValue); isValue {
+		diffs := diffJSON(jm.path, expected.expected, value)
+		return len(diffs) == 0, strings.Join(diffs, "\n")
+	}
+	if matched, explanation := Explain(jm.matcher, value); !matched {
+		return false, fmt.Sprintf("%s: %s", jm.path, indent(explanation, "  "))
+	}
+	return true, ""
+}
+
+func (jm jsonPathMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if jm.err != nil {
+		t.Fatalf("%v is invalid: %s", jm, jm.err.Error())
+	}
+	if !stringable(ft) {
+		t.Fatalf("%v cannot match type %v which is not a string, []byte or fmt.Stringer", jm, ft)
+	}
+	jm.matcher.ForType(t, reflect.TypeOf((*interface{})(nil)).Elem())
+}
+
+/*
+JSONPath matches a single string, []byte or fmt.Stringer argument containing JSON where the value at path matches m
+
+path is a simple JSONPath of object keys and array indexes. eg
+ $.user.id
+ $.items[0].name
+ $['odd.key'][2]
+
+Values are decoded as generic JSON (numbers are float64, objects are map[string]interface{}).
+m may be a Matcher (eg GreaterThan(0), HasPrefix("x")), otherwise it is compared as per JSONEq.
+*/
+func JSONPath(path string, m interface{}) SingleArgMatcher {
+	steps, err := parseJSONPath(path)
+	var matcher SingleArgMatcher
+	switch m.(type) {
+	case Matcher, reflect.Type:
+		matcher = genericSingleArgumentMatcher(m)
+	default:
+		if reflect.TypeOf(m) != nil && reflect.TypeOf(m).Kind() == reflect.Func {
+			matcher = genericSingleArgumentMatcher(m)
+		} else {
+			normalized, normalizeErr := normalizeJSON(m)
+			if err == nil {
+				err = normalizeErr
+			}
+			matcher = jsonValue{normalized}
+		}
+	}
+	return jsonPathMatcher{path: path, steps: steps, matcher: matcher, err: err}
+}
+
+// jsonValue matches an already decoded generic JSON value
+type jsonValue struct {
+	expected interface{}
+}
+
+func (jv jsonValue) String() string {
+	return compactJSON(jv.expected)
+}
+
+func (jv jsonValue) Matches(args ...interface{}) bool {
+	return reflect.DeepEqual(jv.expected, args[0])
+}
+
+func (jv jsonValue) Explain(args ...interface{}) (bool, string) {
+	diffs := diffJSON("$", jv.expected, args[0])
+	return len(diffs) == 0, strings.Join(diffs, "\n")
+}
+
+func (jv jsonValue) ForType(t T, ft reflect.Type) {
+	//decoded values are always interface{}
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestJSONMatchers(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		argType     reflect.Type
+		matching    []interface{}
+		notMatching []interface{}
+		re          string
+	}
+
+	strType := reflect.TypeOf("")
+	rawType := reflect.TypeOf(json.RawMessage{})
+	doc := `{"user": {"id": 42, "name": "fred"}, "items": [{"name": "a"}, {"name": "b"}], "odd.key": [1, 2, 3]}`
+
+	tests := []test{
+		{"JSONEq", JSONEq(`{"a": 1, "b": [true, null]}`), strType,
+			[]interface{}{`{"b":[true,null],"a":1}`, "{\n  \"a\": 1.0,\n  \"b\": [true, null]\n}"},
+			[]interface{}{`{"a": 1}`, `{"a": 1, "b": [null, true]}`, `{"a": 1, "b": [true, null]`, 1},
+			`^JSONEq\(\{"a":1,"b":\[true,null\]\}\)$`},
+		{"JSONEqRaw", JSONEq(map[string]interface{}{"id": 42}), rawType, []interface{}{json.RawMessage(`{ "id" : 42 }`), []byte(`{"id":42}`)}, []interface{}{json.RawMessage(`{"id":"42"}`)}, `^JSONEq\(\{"id":42\}\)$`},
+		{"JSONPath", JSONPath("$.user.id", 42), strType, []interface{}{doc}, []interface{}{`{"user": {"id": 43}}`, `{"user": []}`, `[]`, "bad"}, `^JSONPath\(\$\.user\.id,42\)$`},
+		{"JSONPathMatcher", JSONPath("$.user.name", HasPrefix("f")), strType, []interface{}{doc}, []interface{}{`{"user": {"name": "bob"}}`}, `^JSONPath\(\$\.user\.name,HasPrefix\("f"\)\)$`},
+		{"JSONPathIndex", JSONPath("$.items[1].name", "b"), strType, []interface{}{doc}, []interface{}{`{"items": [{"name": "b"}]}`}, "JSONPath"},
+		{"JSONPathQuoted", JSONPath("$['odd.key'][2]", GreaterThan(2)), strType, []interface{}{doc}, []interface{}{`{"odd.key": [3, 3, 1]}`}, "JSONPath"},
+		{"JSONPathObject", JSONPath("$.user", map[string]interface{}{"name": "fred", "id": 42}), strType, []interface{}{doc}, []interface{}{`{"user": {"name": "fred"}}`}, `^JSONPath\(\$\.user,\{"id":42,"name":"fred"\}\)$`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			matcher := test.matcher
+			if !regexp.MustCompile(test.re).MatchString(fmt.Sprint(matcher)) {
+				t.Errorf("expected '%v' to match '%s'", matcher, test.re)
+			}
+
+			matcher.ForType(t, test.argType)
+
+			for _, arg := range test.matching {
+				if !matcher.Matches(arg) {
+					t.Errorf("Expected %s to match %v", matcher, arg)
+				}
+			}
+			for _, notArg := range test.notMatching {
+				if matcher.Matches(notArg) {
+					t.Errorf("Expected %s to not match %v", matcher, notArg)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONMatchers_FailFatally(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		failType    reflect.Type
+		expectedMsg string
+	}
+
+	strType := reflect.TypeOf("")
+	tests := []test{
+		{"NotString", JSONEq(`{}`), reflect.TypeOf(0), `JSONEq.*int.*not a string`},
+		{"InvalidExpected", JSONEq(`{"a":`), strType, `JSONEq.*invalid.*invalid JSON`},
+		{"PathRoot", JSONPath("user.id", 1), strType, `must start with \$`},
+		{"PathSelector", JSONPath("$.items[x]", 1), strType, `invalid selector \[x\]`},
+		{"PathUnterminated", JSONPath("$.items[0", 1), strType, `unterminated \[`},
+		{"PathEmptyKey", JSONPath("$..id", 1), strType, `empty key`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			test.matcher.ForType(tDouble, test.failType)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
+
+func TestJSONMatchers_Explain(t *testing.T) {
+	tests := []struct {
+		name     string
+		matcher  SingleArgMatcher
+		arg      interface{}
+		expected string
+	}{
+		{"JSONEqDiff", JSONEq(`{"a": 1, "b": [1, 2], "c": {"d": true}}`), `{"a": 2, "b": [1], "e": null}`,
+			"$.a: expected 1, found 2\n$.b: expected 2 elements, found 1\n$.c: missing, expected {\"d\":true}\n$.e: unexpected null"},
+		{"JSONEqInvalid", JSONEq(`{}`), `{`, "invalid JSON: unexpected end of JSON input"},
+		{"JSONPathMissing", JSONPath("$.user.id", 42), `{"user": {}}`, `$.user: has no key "id"`},
+		{"JSONPathIndex", JSONPath("$.items[2]", 1), `{"items": [1]}`, "$.items: has no element [2] in 1 elements"},
+		{"JSONPathValue", JSONPath("$.user", map[string]int{"id": 42}), `{"user": {"id": 43}}`, "$.user.id: expected 42, found 43"},
+		{"JSONPathMatcher", JSONPath("$.n", GreaterThan(5)), `{"n": 1}`, "$.n: 1 did not match GreaterThan(5)"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if _, explanation := Explain(test.matcher, test.arg); explanation != test.expected {
+				t.Errorf("Expected explanation %q, got %q", test.expected, explanation)
+			}
+		})
+	}
+}
+
+func TestJSONMatchers_AsMethodArgs(t *testing.T) {
+	d1 := newApiDouble(t)
+	d1.Stub("call").Matching(JSONPath("$.op", "open")).Returning(1)
+	d1.Stub("call").Returning(2)
+
+	if r := d1.call(`{"op": "open"}`); r != 1 {
+		t.Errorf("Expected 1 for matching JSON, got %d", r)
+	}
+	if r := d1.call(`{"op": "close"}`); r != 2 {
+		t.Errorf("Expected 2 for non matching JSON, got %d", r)
+	}
+}
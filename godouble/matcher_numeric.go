@@ -0,0 +1,245 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// numericMatcher compares a single argument of any integer, unsigned or float kind (including time.Duration)
+type numericMatcher struct {
+	desc   string
+	bounds []interface{}
+	match  func(arg reflect.Value) bool
+}
+
+func (nm numericMatcher) String() string {
+	return nm.desc
+}
+
+func (nm numericMatcher) Matches(args ...interface{}) bool {
+	v := reflect.ValueOf(args[0])
+	if !isNumeric(v.Kind()) {
+		return false
+	}
+	return nm.match(v)
+}
+
+func (nm numericMatcher) Explain(args ...interface{}) (bool, string) {
+	return mismatch(nm, args)
+}
+
+func (nm numericMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	for _, bound := range nm.bounds {
+		if !isNumeric(reflect.ValueOf(bound).Kind()) {
+			t.Fatalf("%v has non numeric bound %v (%T)", nm, bound, bound)
+		}
+	}
+	if ft.Kind() != reflect.Interface && !isNumeric(ft.Kind()) {
+		t.Fatalf("%v cannot compare non numeric type %v", nm, ft)
+	}
+}
+
+func newNumericMatcher(match func(arg reflect.Value) bool, desc string, bounds ...interface{}) numericMatcher {
+	return numericMatcher{desc: desc, bounds: bounds, match: match}
+}
+
+func isNumeric(k reflect.Kind) bool {
+	return isInt(k) || isUint(k) || isFloat(k)
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case isInt(v.Kind()):
+		return float64(v.Int())
+	case isUint(v.Kind()):
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+// compareNumbers returns -1, 0 or 1 as a is less than, equal to or greater than b.
+//
+// ok is false if the values cannot be compared (eg NaN)
+func compareNumbers(a reflect.Value, b reflect.Value) (result int, ok bool) {
+	ak, bk := a.Kind(), b.Kind()
+	switch {
+	case isFloat(ak) || isFloat(bk):
+		af, bf := toFloat(a), toFloat(b)
+		if math.IsNaN(af) || math.IsNaN(bf) {
+			return 0, false
+		}
+		return compare(af < bf, af > bf), true
+	case isInt(ak) && isInt(bk):
+		return compare(a.Int() < b.Int(), a.Int() > b.Int()), true
+	case isUint(ak) && isUint(bk):
+		return compare(a.Uint() < b.Uint(), a.Uint() > b.Uint()), true
+	case isInt(ak):
+		if a.Int() < 0 {
+			return -1, true
+		}
+		return compare(uint64(a.Int()) < b.Uint(), uint64(a.Int()) > b.Uint()), true
+	default:
+		if b.Int() < 0 {
+			return 1, true
+		}
+		return compare(a.Uint() < uint64(b.Int()), a.Uint() > uint64(b.Int())), true
+	}
+}
+
+func compare(less bool, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func comparedTo(bound interface{}, accept func(result int) bool) func(arg reflect.Value) bool {
+	bv := reflect.ValueOf(bound)
+	return func(arg reflect.Value) bool {
+		if !isNumeric(bv.Kind()) {
+			return false
+		}
+		result, ok := compareNumbers(arg, bv)
+		return ok && accept(result)
+	}
+}
+
+// GreaterThan matches a single numeric argument greater than x, which can be any numeric kind
+func GreaterThan(x interface{}) SingleArgMatcher {
+	return newNumericMatcher(comparedTo(x, func(r int) bool { return r > 0 }), fmt.Sprintf("GreaterThan(%v)", x), x)
+}
+
+// GreaterOrEqual matches a single numeric argument greater than or equal to x, which can be any numeric kind
+func GreaterOrEqual(x interface{}) SingleArgMatcher {
+	return newNumericMatcher(comparedTo(x, func(r int) bool { return r >= 0 }), fmt.Sprintf("GreaterOrEqual(%v)", x), x)
+}
+
+// LessThan matches a single numeric argument less than x, which can be any numeric kind
+func LessThan(x interface{}) SingleArgMatcher {
+	return newNumericMatcher(comparedTo(x, func(r int) bool { return r < 0 }), fmt.Sprintf("LessThan(%v)", x), x)
+}
+
+// LessOrEqual matches a single numeric argument less than or equal to x, which can be any numeric kind
+func LessOrEqual(x interface{}) SingleArgMatcher {
+	return newNumericMatcher(comparedTo(x, func(r int) bool { return r <= 0 }), fmt.Sprintf("LessOrEqual(%v)", x), x)
+}
+
+// InRange matches a single numeric argument between lo and hi inclusive
+func InRange(lo interface{}, hi interface{}) SingleArgMatcher {
+	atLeast := comparedTo(lo, func(r int) bool { return r >= 0 })
+	atMost := comparedTo(hi, func(r int) bool { return r <= 0 })
+	return newNumericMatcher(func(arg reflect.Value) bool {
+		return atLeast(arg) && atMost(arg)
+	}, fmt.Sprintf("InRange(%v,%v)", lo, hi), lo, hi)
+}
+
+// ApproxEqual matches a single numeric argument within epsilon of x (compared as float64)
+//
+// eg ApproxEqual(0.3, 1e-9), ApproxEqual(time.Second, 10*time.Millisecond)
+func ApproxEqual(x interface{}, epsilon interface{}) SingleArgMatcher {
+	xv, ev := reflect.ValueOf(x), reflect.ValueOf(epsilon)
+	return newNumericMatcher(func(arg reflect.Value) bool {
+		if !isNumeric(xv.Kind()) || !isNumeric(ev.Kind()) {
+			return false
+		}
+		return math.Abs(toFloat(arg)-toFloat(xv)) <= toFloat(ev)
+	}, fmt.Sprintf("ApproxEqual(%v±%v)", x, epsilon), x, epsilon)
+}
+
+type orderedMatcher struct{}
+
+func (o orderedMatcher) String() string {
+	return "Ordered()"
+}
+
+func (o orderedMatcher) Matches(args ...interface{}) bool {
+	v := reflect.ValueOf(args[0])
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if !isNumeric(v.Type().Elem().Kind()) {
+			return false
+		}
+		for i := 1; i < v.Len(); i++ {
+			if result, ok := compareNumbers(v.Index(i-1), v.Index(i)); !ok || result > 0 {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+func (o orderedMatcher) Explain(args ...interface{}) (bool, string) {
+	v := reflect.ValueOf(args[0])
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && isNumeric(v.Type().Elem().Kind()) {
+		for i := 1; i < v.Len(); i++ {
+			if result, ok := compareNumbers(v.Index(i-1), v.Index(i)); !ok || result > 0 {
+				return false, fmt.Sprintf("element[%d] %v is not ordered after element[%d] %v", i, v.Index(i), i-1, v.Index(i-1))
+			}
+		}
+	}
+	return mismatch(o, args)
+}
+
+func (o orderedMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	switch ft.Kind() {
+	case reflect.Interface:
+		//checked at runtime
+	case reflect.Slice, reflect.Array:
+		if !isNumeric(ft.Elem().Kind()) {
+			t.Fatalf("%v cannot compare non numeric elements of %v", o, ft)
+		}
+	default:
+		t.Fatalf("%v used to match non slice or array type %v", o, ft)
+	}
+}
+
+// Ordered matches a single slice or array argument whose numeric elements are in ascending (non-decreasing) order
+func Ordered() SingleArgMatcher {
+	return orderedMatcher{}
+}
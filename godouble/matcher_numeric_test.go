@@ -0,0 +1,125 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestNumericMatchers(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		argType     reflect.Type
+		matching    []interface{}
+		notMatching []interface{}
+		re          string
+	}
+
+	intType := reflect.TypeOf(0)
+	floatType := reflect.TypeOf(0.0)
+	durationType := reflect.TypeOf(time.Duration(0))
+	ifaceType := reflect.TypeOf((*interface{})(nil)).Elem()
+
+	tests := []test{
+		{"GreaterThan", GreaterThan(10), intType, []interface{}{11, 100}, []interface{}{10, -1}, `^GreaterThan\(10\)$`},
+		{"GreaterThanMixedKinds", GreaterThan(uint8(10)), ifaceType, []interface{}{int64(11), uint(11), 10.5, float32(10.1)}, []interface{}{int8(-1), uint64(10), 9.99, "11", nil}, "GreaterThan"},
+		{"GreaterThanNegative", GreaterThan(-1), ifaceType, []interface{}{uint64(math.MaxUint64), 0}, []interface{}{-2, math.NaN()}, "GreaterThan"},
+		{"GreaterOrEqual", GreaterOrEqual(10), intType, []interface{}{10, 11}, []interface{}{9}, `^GreaterOrEqual\(10\)$`},
+		{"LessThan", LessThan(2.5), floatType, []interface{}{2.4, math.Inf(-1)}, []interface{}{2.5, math.NaN()}, `^LessThan\(2.5\)$`},
+		{"LessThanUnsignedBound", LessThan(uint64(math.MaxUint64)), ifaceType, []interface{}{-1, uint64(math.MaxUint64 - 1)}, []interface{}{uint64(math.MaxUint64)}, "LessThan"},
+		{"LessOrEqual", LessOrEqual(0), intType, []interface{}{0, -1}, []interface{}{1}, `^LessOrEqual\(0\)$`},
+		{"InRange", InRange(1, 3), intType, []interface{}{1, 2, 3}, []interface{}{0, 4}, `^InRange\(1,3\)$`},
+		{"InRangeDuration", InRange(time.Second, time.Minute), durationType, []interface{}{time.Second, 30 * time.Second}, []interface{}{time.Millisecond, time.Hour}, `^InRange\(1s,1m0s\)$`},
+		{"ApproxEqual", ApproxEqual(0.3, 1e-9), floatType, []interface{}{0.1 + 0.2, 0.3}, []interface{}{0.31, math.NaN()}, `^ApproxEqual\(0.3±1e-09\)$`},
+		{"ApproxEqualDuration", ApproxEqual(time.Second, 10*time.Millisecond), durationType, []interface{}{time.Second + 5*time.Millisecond, 995 * time.Millisecond}, []interface{}{time.Second + 11*time.Millisecond}, `^ApproxEqual\(1s±10ms\)$`},
+		{"Ordered", Ordered(), reflect.TypeOf([]int{}), []interface{}{[]int{1, 2, 2, 3}, []int{}, []int(nil)}, []interface{}{[]int{2, 1}}, `^Ordered\(\)$`},
+		{"OrderedIface", Ordered(), ifaceType, []interface{}{[2]float64{1.5, 2}, []time.Duration{time.Millisecond, time.Second}}, []interface{}{[]float64{1, math.NaN()}, []string{"a", "b"}, 1, nil}, "Ordered"},
+		{"NotGreaterThan", Not(GreaterThan(5)), intType, []interface{}{5}, []interface{}{6}, `Not\(GreaterThan\(5\)\)`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			matcher := test.matcher
+			if !regexp.MustCompile(test.re).MatchString(fmt.Sprint(matcher)) {
+				t.Errorf("expected '%v' to match '%s'", matcher, test.re)
+			}
+
+			matcher.ForType(t, test.argType)
+
+			for _, arg := range test.matching {
+				if !matcher.Matches(arg) {
+					t.Errorf("Expected %s to match %v", matcher, arg)
+				}
+			}
+			for _, notArg := range test.notMatching {
+				if matcher.Matches(notArg) {
+					t.Errorf("Expected %s to not match %v", matcher, notArg)
+				}
+			}
+		})
+	}
+}
+
+func TestNumericMatchers_FailFatally(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		failType    reflect.Type
+		expectedMsg string
+	}
+
+	tests := []test{
+		{"NonNumeric", GreaterThan(1), reflect.TypeOf(""), "GreaterThan.*non numeric type string"},
+		{"NonNumericBound", InRange(1, "10"), reflect.TypeOf(0), `InRange.*non numeric bound 10 \(string\)`},
+		{"OrderedNonSlice", Ordered(), reflect.TypeOf(0), "Ordered.*non slice or array type int"},
+		{"OrderedNonNumeric", Ordered(), reflect.TypeOf([]string{}), `Ordered.*non numeric elements of \[\]string`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			test.matcher.ForType(tDouble, test.failType)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
+
+func TestNumericMatchers_AsMethodArgs(t *testing.T) {
+	d1 := newApiDouble(t)
+	d1.Stub("test").Matching(InRange(1, 5), All()).Returning(1, nil)
+	d1.Stub("test").Returning(2, nil)
+
+	if r, _ := d1.test(3, "x"); r != 1 {
+		t.Errorf("Expected 1 for value in range, got %d", r)
+	}
+	if r, _ := d1.test(6, "x"); r != 2 {
+		t.Errorf("Expected 2 for value out of range, got %d", r)
+	}
+}
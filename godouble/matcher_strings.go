@@ -0,0 +1,208 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// stringMatcher matches a single argument of string kind, []byte or fmt.Stringer
+type stringMatcher struct {
+	desc  string
+	match func(s string) bool
+	err   error //invalid pattern, reported by ForType
+}
+
+func (sm stringMatcher) String() string {
+	return sm.desc
+}
+
+func (sm stringMatcher) Matches(args ...interface{}) bool {
+	if sm.err != nil {
+		return false
+	}
+	if s, isString := stringOf(args[0]); isString {
+		return sm.match(s)
+	}
+	return false
+}
+
+func (sm stringMatcher) Explain(args ...interface{}) (bool, string) {
+	return mismatch(sm, args)
+}
+
+func (sm stringMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if sm.err != nil {
+		t.Fatalf("%v is invalid: %s", sm, sm.err.Error())
+	}
+	if !stringable(ft) {
+		t.Fatalf("%v cannot match type %v which is not a string, []byte or fmt.Stringer", sm, ft)
+	}
+}
+
+func stringOf(arg interface{}) (string, bool) {
+	if arg == nil {
+		return "", false
+	}
+	v := reflect.ValueOf(arg)
+	if v.Kind() == reflect.String {
+		return v.String(), true
+	}
+	//Stringer takes precedence over []byte, eg for net.IP
+	if stringer, isStringer := arg.(fmt.Stringer); isStringer {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return "", false
+		}
+		return stringer.String(), true
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return string(v.Bytes()), true
+	}
+	return "", false
+}
+
+func stringable(ft reflect.Type) bool {
+	switch {
+	case ft.Kind() == reflect.String, ft.Kind() == reflect.Interface:
+		return true
+	case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Uint8:
+		return true
+	default:
+		return ft.Implements(stringerType)
+	}
+}
+
+func newStringMatcher(match func(s string) bool, explanation ...interface{}) stringMatcher {
+	return stringMatcher{desc: fmt.Sprint(explanation...), match: match}
+}
+
+// Regexp matches a single string, []byte or fmt.Stringer argument that contains a match of the regular expression pattern
+//
+// An invalid pattern will fatally fail the test when the matcher is used.
+func Regexp(pattern string) SingleArgMatcher {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return stringMatcher{desc: fmt.Sprintf("Regexp(/%s/)", pattern), err: err}
+	}
+	return newStringMatcher(re.MatchString, "Regexp(/", pattern, "/)")
+}
+
+// HasPrefix matches a single string, []byte or fmt.Stringer argument that begins with prefix
+func HasPrefix(prefix string) SingleArgMatcher {
+	return newStringMatcher(func(s string) bool {
+		return strings.HasPrefix(s, prefix)
+	}, fmt.Sprintf("HasPrefix(%q)", prefix))
+}
+
+// HasSuffix matches a single string, []byte or fmt.Stringer argument that ends with suffix
+func HasSuffix(suffix string) SingleArgMatcher {
+	return newStringMatcher(func(s string) bool {
+		return strings.HasSuffix(s, suffix)
+	}, fmt.Sprintf("HasSuffix(%q)", suffix))
+}
+
+// ContainsSubstring matches a single string, []byte or fmt.Stringer argument that contains substr
+func ContainsSubstring(substr string) SingleArgMatcher {
+	return newStringMatcher(func(s string) bool {
+		return strings.Contains(s, substr)
+	}, fmt.Sprintf("ContainsSubstring(%q)", substr))
+}
+
+// EqualFold matches a single string, []byte or fmt.Stringer argument that is equal to expected under Unicode case folding
+func EqualFold(expected string) SingleArgMatcher {
+	return newStringMatcher(func(s string) bool {
+		return strings.EqualFold(s, expected)
+	}, fmt.Sprintf("EqualFold(%q)", expected))
+}
+
+/*
+Glob matches a single string, []byte or fmt.Stringer argument against the shell pattern
+
+  '*'         matches any sequence of characters (including '/')
+  '?'         matches any single character
+  '[' ']'     matches a character class, negated with a leading '!' or '^'
+  '\'         matches the following character literally
+
+An invalid pattern will fatally fail the test when the matcher is used.
+*/
+func Glob(pattern string) SingleArgMatcher {
+	desc := fmt.Sprintf("Glob(%q)", pattern)
+	expr, err := globToRegexp(pattern)
+	if err != nil {
+		return stringMatcher{desc: desc, err: err}
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return stringMatcher{desc: desc, err: err}
+	}
+	return newStringMatcher(re.MatchString, desc)
+}
+
+func globToRegexp(pattern string) (string, error) {
+	sb := strings.Builder{}
+	sb.WriteString(`^(?s:`)
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			sb.WriteString(`.*`)
+		case '?':
+			sb.WriteString(`.`)
+		case '\\':
+			if i++; i == len(runes) {
+				return "", fmt.Errorf("trailing escape in glob pattern %q", pattern)
+			}
+			sb.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end := i + 1
+			if end < len(runes) && (runes[end] == '!' || runes[end] == '^') {
+				end++
+			}
+			if end < len(runes) && runes[end] == ']' {
+				end++
+			}
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return "", fmt.Errorf("unterminated character class in glob pattern %q", pattern)
+			}
+			class := runes[i+1 : end]
+			sb.WriteRune('[')
+			if class[0] == '!' || class[0] == '^' {
+				sb.WriteRune('^')
+				class = class[1:]
+			}
+			for _, c := range class {
+				if c == '\\' || c == '[' || c == ']' {
+					sb.WriteRune('\\')
+				}
+				sb.WriteRune(c)
+			}
+			sb.WriteRune(']')
+			i = end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString(`)$`)
+	return sb.String(), nil
+}
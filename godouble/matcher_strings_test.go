@@ -0,0 +1,131 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestStringMatchers(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		argType     reflect.Type
+		matching    []interface{}
+		notMatching []interface{}
+		re          string
+	}
+
+	strType := reflect.TypeOf("")
+	bytesType := reflect.TypeOf([]byte{})
+	stringerType := reflect.TypeOf(net.IP{})
+	ifaceType := reflect.TypeOf((*interface{})(nil)).Elem()
+	var nilIP net.IP
+
+	tests := []test{
+		{"Regexp", Regexp("^user-[0-9]+$"), strType, []interface{}{"user-1", tstring("user-22")}, []interface{}{"user-", "auser-1", 10}, `^Regexp\(/\^user-\[0-9\]\+\$/\)$`},
+		{"RegexpBytes", Regexp("b+"), bytesType, []interface{}{[]byte("abbc")}, []interface{}{[]byte("ac"), []byte(nil)}, "Regexp"},
+		{"RegexpStringer", Regexp(`^127\.`), stringerType, []interface{}{net.IPv4(127, 0, 0, 1)}, []interface{}{net.IPv4(10, 0, 0, 1)}, "Regexp"},
+		{"HasPrefix", HasPrefix("pre"), strType, []interface{}{"prefix", "pre"}, []interface{}{"pr", "apre"}, `^HasPrefix\("pre"\)$`},
+		{"HasSuffix", HasSuffix("fix"), strType, []interface{}{"suffix", "fix"}, []interface{}{"fixed", ""}, `^HasSuffix\("fix"\)$`},
+		{"ContainsSubstring", ContainsSubstring("ain"), ifaceType, []interface{}{"contains", []byte("rain")}, []interface{}{"an", nil, 42, nilIP}, `^ContainsSubstring\("ain"\)$`},
+		{"EqualFold", EqualFold("Go"), strType, []interface{}{"GO", "go", "Go"}, []interface{}{"goo", "g"}, `^EqualFold\("Go"\)$`},
+		{"Glob", Glob("*.go"), strType, []interface{}{"main.go", ".go", "dir/main.go"}, []interface{}{"main.gox", "main_go"}, `^Glob\("\*\.go"\)$`},
+		{"GlobSingle", Glob("file?.[ch]"), strType, []interface{}{"file1.c", "fileX.h"}, []interface{}{"file.c", "file12.c", "file1.go"}, "Glob"},
+		{"GlobNegatedClass", Glob("[!a-c]*"), strType, []interface{}{"dog", "z"}, []interface{}{"apple", "cat", ""}, "Glob"},
+		{"GlobEscaped", Glob(`\*\?`), strType, []interface{}{"*?"}, []interface{}{"ab"}, "Glob"},
+		{"NotHasPrefix", Not(HasPrefix("x")), strType, []interface{}{"abc"}, []interface{}{"xyz"}, `Not\(HasPrefix`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			matcher := test.matcher
+			if !regexp.MustCompile(test.re).MatchString(fmt.Sprint(matcher)) {
+				t.Errorf("expected '%v' to match '%s'", matcher, test.re)
+			}
+
+			matcher.ForType(t, test.argType)
+
+			for _, arg := range test.matching {
+				if !matcher.Matches(arg) {
+					t.Errorf("Expected %s to match %v", matcher, arg)
+				}
+			}
+			for _, notArg := range test.notMatching {
+				if matcher.Matches(notArg) {
+					t.Errorf("Expected %s to not match %v", matcher, notArg)
+				}
+			}
+		})
+	}
+}
+
+func TestStringMatchers_FailFatally(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		failType    reflect.Type
+		expectedMsg string
+	}
+
+	strType := reflect.TypeOf("")
+	tests := []test{
+		{"NonString", HasPrefix("x"), reflect.TypeOf(0), "HasPrefix.*int.*not a string"},
+		{"IntSlice", Regexp("x"), reflect.TypeOf([]int{}), `\[\]int.*not a string`},
+		{"BadRegexp", Regexp("(x"), strType, "Regexp.*invalid.*missing closing"},
+		{"BadGlobClass", Glob("[abc"), strType, "Glob.*invalid.*unterminated"},
+		{"BadGlobEscape", Glob(`abc\`), strType, "Glob.*invalid.*trailing escape"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			test.matcher.ForType(tDouble, test.failType)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
+
+func TestStringMatchers_AsMethodArgs(t *testing.T) {
+	d1 := newApiDouble(t)
+	d1.Stub("call").Matching(HasPrefix("user-")).Returning(1)
+	d1.Stub("call").Returning(2)
+
+	if r := d1.call("user-10"); r != 1 {
+		t.Errorf("Expected 1 for matching prefix, got %d", r)
+	}
+	if r := d1.call("admin"); r != 2 {
+		t.Errorf("Expected 2 for non matching prefix, got %d", r)
+	}
+
+	d2 := newApiDouble(t)
+	spy := d2.Spy("call")
+	d2.call("user-10")
+	d2.call("user-x")
+	spy.Matching(Regexp("^user-[0-9]+$")).Expect(Once())
+}
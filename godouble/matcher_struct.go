@@ -0,0 +1,239 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type fieldMatcher struct {
+	path    string
+	names   []string
+	matcher SingleArgMatcher
+}
+
+func (f fieldMatcher) String() string {
+	return fmt.Sprintf("%s:%v", f.path, f.matcher)
+}
+
+// resolve follows the field path through structs, pointers and interfaces.
+//
+// reason is non empty if the path cannot be followed for this value
+func (f fieldMatcher) resolve(arg interface{}) (v reflect.Value, reason string) {
+	v = reflect.ValueOf(arg)
+	for i, name := range f.names {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() && i == 0 {
+				return v, fmt.Sprintf("field %s: nil %v", f.path, v.Type())
+			} else if v.IsNil() {
+				return v, fmt.Sprintf("field %s: nil %v", strings.Join(f.names[:i], "."), v.Type())
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return v, fmt.Sprintf("field %s: %v is not a struct", f.path, describeType(v))
+		}
+		sf, found := v.Type().FieldByName(name)
+		if !found || sf.PkgPath != "" || promotedThroughUnexported(v.Type(), sf) {
+			return v, fmt.Sprintf("field %s: no exported field %q", f.path, name)
+		}
+		for j, index := range sf.Index {
+			if j > 0 && v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return v, fmt.Sprintf("field %s: nil embedded %s", f.path, v.Type())
+				}
+				v = v.Elem()
+			}
+			v = v.Field(index)
+		}
+	}
+	return v, ""
+}
+
+// promotedThroughUnexported is true if sf is promoted to st through an embedded struct that is not exported
+func promotedThroughUnexported(st reflect.Type, sf reflect.StructField) bool {
+	for j := 1; j < len(sf.Index); j++ {
+		if st.FieldByIndex(sf.Index[:j]).PkgPath != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fieldMatcher) Matches(args ...interface{}) bool {
+	v, reason := f.resolve(args[0])
+	return reason == "" && f.matcher.Matches(v.Interface())
+}
+
+func (f fieldMatcher) Explain(args ...interface{}) (bool, string) {
+	v, reason := f.resolve(args[0])
+	if reason != "" {
+		return false, reason
+	}
+	if matched, explanation := Explain(f.matcher, v.Interface()); !matched {
+		return false, fmt.Sprintf("field %s: %s", f.path, indent(explanation, "  "))
+	}
+	return true, ""
+}
+
+func (f fieldMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	if f.path == "" {
+		t.Fatalf("Field() requires a non empty path")
+	}
+	for _, name := range f.names {
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Interface {
+			//path checked at runtime
+			return
+		}
+		if ft.Kind() != reflect.Struct {
+			t.Fatalf("%v cannot select field %q from non struct type %v", f, name, ft)
+		}
+		sf, found := ft.FieldByName(name)
+		if !found {
+			t.Fatalf("%v type %v has no field %q", f, ft, name)
+		}
+		if sf.PkgPath != "" {
+			t.Fatalf("%v cannot match unexported field %q of type %v", f, name, ft)
+		}
+		if promotedThroughUnexported(ft, sf) {
+			t.Fatalf("%v cannot match field %q promoted through an unexported embedded struct of type %v", f, name, ft)
+		}
+		ft = sf.Type
+	}
+	f.matcher.ForType(t, ft)
+}
+
+func describeType(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return v.Type().String()
+}
+
+// Field matches a single struct (or pointer to struct) argument where the field at the dot separated path matches m
+//
+// Pointers, including embedded pointers, are followed along the path, with a nil pointer not matching. Fields promoted
+// through an unexported embedded struct cannot be matched.
+// m may be anything that can match a single argument. eg Field("User.ID", 42), Field("Opts.Retry", GreaterThan(0))
+//
+// The path is validated against the argument type when the matcher is used.
+func Field(path string, m interface{}) SingleArgMatcher {
+	return fieldMatcher{path: path, names: strings.Split(path, "."), matcher: genericSingleArgumentMatcher(m)}
+}
+
+type fieldsMatcher struct {
+	fields matcherList
+}
+
+func (f fieldsMatcher) String() string {
+	return f.fields.toString("Fields", '(', ')')
+}
+
+func (f fieldsMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	f.fields.ForType(t, ft)
+}
+
+func (f fieldsMatcher) Matches(args ...interface{}) bool {
+	for _, m := range f.fields {
+		if !m.Matches(args...) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f fieldsMatcher) Explain(args ...interface{}) (bool, string) {
+	return explainAll(f.fields, args)
+}
+
+// Fields matches a single struct argument if all the field matchers match
+//
+// eg Fields(Field("User.ID", Eql(42)), Field("Opts.Retry", GreaterThan(0)))
+//
+// When a call does not match, the explanation names each field that did not match.
+func Fields(fields ...SingleArgMatcher) SingleArgMatcher {
+	matchers := make(matcherList, len(fields))
+	for i, field := range fields {
+		matchers[i] = field
+	}
+	return fieldsMatcher{matchers}
+}
+
+type ptrMatcher struct {
+	matcher SingleArgMatcher
+}
+
+func (p ptrMatcher) String() string {
+	return fmt.Sprintf("Ptr(%v)", p.matcher)
+}
+
+func (p ptrMatcher) deref(arg interface{}) (interface{}, bool) {
+	v := reflect.ValueOf(arg)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, false
+	}
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Ptr {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+func (p ptrMatcher) Matches(args ...interface{}) bool {
+	value, ok := p.deref(args[0])
+	return ok && p.matcher.Matches(value)
+}
+
+func (p ptrMatcher) Explain(args ...interface{}) (bool, string) {
+	value, ok := p.deref(args[0])
+	if !ok {
+		return false, fmt.Sprintf("%v: nil pointer", p)
+	}
+	return Explain(p.matcher, value)
+}
+
+func (p ptrMatcher) ForType(t T, ft reflect.Type) {
+	t.Helper()
+	switch ft.Kind() {
+	case reflect.Interface:
+		//checked at runtime
+	case reflect.Ptr:
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		p.matcher.ForType(t, ft)
+	default:
+		t.Fatalf("%v used to match non pointer type %v", p, ft)
+	}
+}
+
+// Ptr matches a single non nil pointer argument where the value pointed to matches m
+//
+// Pointers to pointers are followed to the underlying value. m may be anything that can match a single argument.
+// eg Ptr(42), Ptr(Fields(Field("ID", 42)))
+func Ptr(m interface{}) SingleArgMatcher {
+	return ptrMatcher{genericSingleArgumentMatcher(m)}
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+type testUser struct {
+	ID   int
+	Name string
+}
+
+type testOpts struct {
+	Retry int
+}
+
+type testRequest struct {
+	User    *testUser
+	Opts    testOpts
+	Payload interface{}
+	secret  string
+}
+
+// TestBase is embedded in testEmbedding by pointer
+type TestBase struct {
+	ID int
+}
+
+type testBase struct {
+	Code int
+}
+
+type testEmbedding struct {
+	*TestBase
+	testBase
+	Name string
+}
+
+type requestApi interface {
+	send(req *testRequest) error
+}
+
+type requestApiDouble struct {
+	requestApi
+	*TestDouble
+}
+
+func (d *requestApiDouble) send(req *testRequest) error {
+	d.TestDouble.T().Helper()
+	err, _ := d.Invoke("send", req)[0].(error)
+	return err
+}
+
+func TestStructMatchers(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		argType     reflect.Type
+		matching    []interface{}
+		notMatching []interface{}
+		re          string
+	}
+
+	reqType := reflect.TypeOf(testRequest{})
+	reqPtrType := reflect.TypeOf(&testRequest{})
+	ifaceType := reflect.TypeOf((*interface{})(nil)).Elem()
+	req := testRequest{User: &testUser{ID: 42, Name: "fred"}, Opts: testOpts{Retry: 3}, Payload: testOpts{Retry: 1}}
+	other := testRequest{User: &testUser{ID: 7}}
+	noUser := testRequest{}
+	i := 10
+	embedding := testEmbedding{TestBase: &TestBase{ID: 1}, Name: "x"}
+
+	tests := []test{
+		{"Field", Field("User.ID", 42), reqType, []interface{}{req, &req}, []interface{}{other, noUser, nil, 42}, `^User.ID:Eql\(42\)$`},
+		{"FieldIface", Field("Payload.Retry", GreaterThan(0)), ifaceType, []interface{}{req}, []interface{}{other, testUser{}}, "Payload.Retry"},
+		{"Fields", Fields(Field("User.ID", Eql(42)), Field("Opts.Retry", GreaterThan(0))), reqPtrType, []interface{}{&req}, []interface{}{&other, &noUser}, `^Fields\(User.ID:Eql\(42\),Opts.Retry:GreaterThan\(0\)\)$`},
+		{"Promoted", Field("ID", 1), reflect.TypeOf(embedding), []interface{}{embedding, &embedding}, []interface{}{testEmbedding{Name: "x"}, testEmbedding{TestBase: &TestBase{ID: 2}}}, `^ID:Eql\(1\)$`},
+		{"PromotedUnexported", Field("Code", 0), ifaceType, []interface{}{}, []interface{}{embedding}, `^Code:Eql\(0\)$`},
+		{"Ptr", Ptr(10), reflect.TypeOf(&i), []interface{}{&i}, []interface{}{(*int)(nil), 10}, `^Ptr\(Eql\(10\)\)$`},
+		{"PtrFields", Ptr(Fields(Field("User.Name", "fred"))), reqPtrType, []interface{}{&req}, []interface{}{&other, (*testRequest)(nil)}, `^Ptr\(Fields\(`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			matcher := test.matcher
+			if !regexp.MustCompile(test.re).MatchString(fmt.Sprint(matcher)) {
+				t.Errorf("expected '%v' to match '%s'", matcher, test.re)
+			}
+
+			matcher.ForType(t, test.argType)
+
+			for _, arg := range test.matching {
+				if !matcher.Matches(arg) {
+					t.Errorf("Expected %s to match %v", matcher, arg)
+				}
+			}
+			for _, notArg := range test.notMatching {
+				if matcher.Matches(notArg) {
+					t.Errorf("Expected %s to not match %v", matcher, notArg)
+				}
+			}
+		})
+	}
+}
+
+func TestStructMatchers_FailFatally(t *testing.T) {
+	type test struct {
+		name        string
+		matcher     SingleArgMatcher
+		failType    reflect.Type
+		expectedMsg string
+	}
+
+	reqType := reflect.TypeOf(testRequest{})
+	tests := []test{
+		{"NoSuchField", Field("User.Email", "x"), reqType, `User.Email.*testUser has no field "Email"`},
+		{"NonStruct", Field("Opts.Retry.Count", 1), reqType, `cannot select field "Count" from non struct type int`},
+		{"Unexported", Field("secret", "x"), reqType, `unexported field "secret"`},
+		{"FieldType", Field("User.ID", HasPrefix("x")), reqType, "HasPrefix.*int.*not a string"},
+		{"PromotedUnexported", Field("Code", 0), reflect.TypeOf(testEmbedding{}), `field "Code" promoted through an unexported embedded struct`},
+		{"NotPointer", Ptr(1), reflect.TypeOf(0), `Ptr\(Eql\(1\)\) used to match non pointer type int`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			test.matcher.ForType(tDouble, test.failType)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
+
+func TestStructMatchers_ExplainFailingField(t *testing.T) {
+	doubleT := NewTDouble(t, func(c *TestDouble) {
+		//c.EnableTrace()
+	})
+	spy := doubleT.Spy("Errorf")
+
+	d1 := &requestApiDouble{TestDouble: NewDouble(doubleT, (*requestApi)(nil), func(c *TestDouble) { c.DisableTrace() })}
+	sent := d1.Spy("send")
+	_ = d1.send(&testRequest{User: &testUser{ID: 42}, Opts: testOpts{Retry: 1}})
+	_ = d1.send(&testRequest{User: &testUser{ID: 42}})
+	_ = d1.send(&testRequest{Opts: testOpts{Retry: 1}})
+
+	sent.Matching(Fields(Field("User.ID", 42), Field("Opts.Retry", GreaterThan(0)))).Expect(Once())
+	spy.Expect(Never())
+
+	sent.Where(Fields(Field("User.ID", 42), Field("Opts.Retry", GreaterThan(0)))).All()
+	spy.Matching(printfMatcher(`(?s)found 2 that did not:` +
+		`\n  tick \d+: \[.*\]\n    arg\[0\]: field Opts.Retry: 0 did not match GreaterThan\(0\)` +
+		`\n  tick \d+: \[.*\]\n    arg\[0\]: field User: nil \*godouble.testUser$`)).Expect(Once())
+}
+
+func TestStructMatchers_ExplainNilEmbedded(t *testing.T) {
+	if matched, explanation := Explain(Field("ID", 1), testEmbedding{Name: "x"}); matched || explanation != "field ID: nil embedded *godouble.TestBase" {
+		t.Errorf("Expected nil embedded explanation, got %v, %s", matched, explanation)
+	}
+}
@@ -194,9 +194,63 @@ func TestSingleArgMatchers(t *testing.T) {
 					t.Errorf("Expectes %s to not match %v", matcher, notArg)
 				}
 			}
+
+			if _, isExplaining := matcher.(ExplainingMatcher); !isExplaining {
+				t.Errorf("Expected %s to be an ExplainingMatcher", matcher)
+			}
+			for _, arg := range matching {
+				if matched, explanation := Explain(matcher, arg); !matched || explanation != "" {
+					t.Errorf("Expected %s to explain %v as matching, got %q", matcher, arg, explanation)
+				}
+			}
+			for _, notArg := range notMatching {
+				if matched, explanation := Explain(matcher, notArg); matched || explanation == "" {
+					t.Errorf("Expected %s to explain why %v does not match", matcher, notArg)
+				}
+			}
 		})
 	}
 }
+
+// unexplainedMatcher is not an ExplainingMatcher, and matches nothing
+type unexplainedMatcher struct{}
+
+func (u unexplainedMatcher) String() string {
+	return "Unexplained"
+}
+
+func (u unexplainedMatcher) Matches(args ...interface{}) bool {
+	return false
+}
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name     string
+		matcher  Matcher
+		args     []interface{}
+		expected string
+	}{
+		{"Default", Eql("x"), []interface{}{"y"}, `"y" did not match Eql(x)`},
+		{"NotExplaining", unexplainedMatcher{}, []interface{}{"y", 1}, `("y", 1) did not match Unexplained`},
+		{"Args", Args(Eql("x"), Eql(1)), []interface{}{"y", 2}, "arg[0]: \"y\" did not match Eql(x)\narg[1]: 2 did not match Eql(1)"},
+		{"All", All(Len(3), HasPrefix("x")), []interface{}{"yy"}, "len(): 2 did not match Eql(3)\n\"yy\" did not match HasPrefix(\"x\")"},
+		{"Any", Any(Eql("x"), Len(3)), []interface{}{"yy"}, "\"yy\" did not match any of Any{Eql(x),Len(Eql(3))}\n  \"yy\" did not match Eql(x)\n  len(): 2 did not match Eql(3)"},
+		{"Not", Not(Eql("x")), []interface{}{"x"}, `"x" matched Eql(x)`},
+		{"SliceElement", Slice(Eql(1), Eql(2)), []interface{}{[]int{1, 3}}, "element[1]: 3 did not match Eql(2)"},
+		{"SliceLength", Slice(Eql(1), Eql(2)), []interface{}{[]int{1}}, "expected at least 2 elements, found 1"},
+		{"LenNoLength", Len(0), []interface{}{1}, "int has no length"},
+		{"Tree", Args(Slice(Any(Eql(1), Len(2)))), []interface{}{[][]int{{3}}}, "arg[0]: element[0]: [3] did not match any of Any{Eql(1),Len(Eql(2))}\n      [3] did not match Eql(1)\n      len(): 1 did not match Eql(2)"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if matched, explanation := Explain(test.matcher, test.args...); matched || explanation != test.expected {
+				t.Errorf("Expected explanation %q, got %v %q", test.expected, matched, explanation)
+			}
+		})
+	}
+}
+
 func TestSingleArgMatcher_FailsFatally(t *testing.T) {
 	type test struct {
 		name        string
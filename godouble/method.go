@@ -19,7 +19,9 @@ package godouble
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // Method is used to configure the default Double type for a given interface method.
@@ -87,6 +89,16 @@ func (m *method) match(args []interface{}) (matched MethodCall) {
 			return possible
 		}
 	}
+	for _, possible := range m.calls {
+		if mock, isMock := possible.(*mockedMethodCall); isMock {
+			mock.outOfSequence(args)
+		}
+	}
+	if m.trace() {
+		if explanation := m.explainMismatches(args, nil); explanation != "" {
+			m.t().Logf("%v(%v) did not match:%s", m, args, explanation)
+		}
+	}
 	defaultMatcher := m.receiver.defaultCall(m)
 	if defaultMatcher == nil {
 		m.t().Fatalf("Nil DefaultMethodCall returned for %v", m)
@@ -97,10 +109,49 @@ func (m *method) match(args []interface{}) (matched MethodCall) {
 
 	return defaultMatcher
 }
+// explainMismatches describes why args did not match each of the registered calls (other than skip)
+func (m *method) explainMismatches(args []interface{}, skip MethodCall) string {
+	sb := strings.Builder{}
+	for _, call := range m.calls {
+		if call == skip {
+			continue
+		}
+		if matched, explanation := call.explain(args); !matched {
+			fmt.Fprintf(&sb, "\n  %v\n    %s", call, indent(explanation, "    "))
+		}
+	}
+	return sb.String()
+}
+
 func (m *method) invoke(args []interface{}) []interface{} {
+	matched, returns, err := m.call(args)
+	if err != nil {
+		m.t().Fatalf("No return values available for method %v(%v) %s", matched, args, err.Error())
+	} else {
+		if m.trace() {
+			m.t().Logf("Called %s(%v) => %v", matched, args, returns)
+		}
+		AssertMethodReturnValues(m.t(), m.m, returns) //Safe but slow?
+	}
+	return returns
+}
+
+// call matches and records a call, and receives the values for its results
+//
+// ReturnValues that park calls (eg a Gate) are received outside the mutex, so they do not block other calls
+func (m *method) call(args []interface{}) (matched MethodCall, returns []interface{}, err error) {
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
-	matched := m.match(args)
+	locked := true
+	defer func() {
+		if locked {
+			m.mutex.Unlock()
+		}
+	}()
+	matched = m.match(args)
+
+	if transcript := m.receiver.transcript; transcript != nil {
+		transcript.record(m, args)
+	}
 
 	if m.trace() {
 		m.t().Helper()
@@ -113,16 +164,17 @@ func (m *method) invoke(args []interface{}) []interface{} {
 		}(matched, args)
 	}
 
-	returns, err := matched.spy(args)
-	if err != nil {
-		m.t().Fatalf("No return values available for method %v(%v) %s", matched, args, err.Error())
-	} else {
-		if m.trace() {
-			m.t().Logf("Called %s(%v) => %v", matched, args, returns)
-		}
-		AssertMethodReturnValues(m.t(), m.m, returns) //Safe but slow?
+	returnValues := matched.spy(args)
+	if parks(returnValues) {
+		m.mutex.Unlock()
+		locked = false
 	}
-	return returns
+	returns, err = returnValues.Receive()
+	return
+}
+
+func (m *method) newRecordedCall(args []interface{}) *recordedCall {
+	return &recordedCall{args: args, tick: atomic.AddUint64(&tick, 1), at: m.receiver.clock()}
 }
 
 func (m *method) defaultReturnValues() ReturnValues {
@@ -16,6 +16,11 @@
 
 package godouble
 
+import (
+	"fmt"
+	"strings"
+)
+
 //MockedMethodCall is a MethodCall that has pre-defined expectations for how often and sequence of invocations
 type MockedMethodCall interface {
 	/*
@@ -33,6 +38,9 @@ type MockedMethodCall interface {
 	//Setup that this call will only match if the supplied calls are already complete
 	After(calls ...MockedMethodCall) MockedMethodCall
 
+	//Setup that this call is the next member of seq
+	InSequence(seq *CallSequence) MockedMethodCall
+
 	/*
 		Returning is used to setup return values for this call
 
@@ -46,25 +54,42 @@ type MockedMethodCall interface {
 	MethodCall
 
 	complete() bool
+	calls() []*recordedCall
 }
 
 type mockedMethodCall struct {
 	*stubbedMethodCall
-	count  int
-	after  []MockedMethodCall
-	expect Expectation
+	recorded  []*recordedCall
+	after     []MockedMethodCall
+	sequences []sequencePosition
+	expect    Expectation
+}
+
+func (c *mockedMethodCall) count() int {
+	return len(c.recorded)
+}
+
+func (c *mockedMethodCall) calls() []*recordedCall {
+	return c.recorded
 }
 
 func (c *mockedMethodCall) complete() bool {
 	if completion, isCompletion := c.expect.(Completion); isCompletion {
-		return completion.Complete(c.count)
+		return completion.Complete(c.count())
 	}
 	return false
 }
 
+func (c *mockedMethodCall) incomplete() []MockedMethodCall {
+	if c.complete() {
+		return nil
+	}
+	return []MockedMethodCall{c}
+}
+
 func (c *mockedMethodCall) met() bool {
 	if c.expect != nil {
-		return c.expect.Met(c.count)
+		return expectationMet(c.expect, c.receiver.started, c.recorded)
 	}
 	return true
 }
@@ -73,7 +98,7 @@ func newMockedMethodCall(m *method) MockedMethodCall {
 
 	call := &mockedMethodCall{
 		stubbedMethodCall: newStubbedMethodCall(m),
-		count:             0,
+		recorded:          []*recordedCall{},
 		after:             []MockedMethodCall{},
 	}
 	return call
@@ -91,6 +116,11 @@ func (c *mockedMethodCall) After(after ...MockedMethodCall) MockedMethodCall {
 	return c
 }
 
+func (c *mockedMethodCall) InSequence(seq *CallSequence) MockedMethodCall {
+	c.sequences = append(c.sequences, sequencePosition{seq, seq.add(c)})
+	return c
+}
+
 func (c *mockedMethodCall) Returning(values ...interface{}) MockedMethodCall {
 	c.stubbedMethodCall.Returning(values...)
 	return c
@@ -101,24 +131,69 @@ func (c *mockedMethodCall) Expect(expect Expectation) MockedMethodCall {
 	return c
 }
 
-func (c *mockedMethodCall) inSequence() bool {
+// blockers returns the incomplete calls that are required to complete before this call
+func (c *mockedMethodCall) blockers() (calls []MockedMethodCall) {
 	for _, call := range c.after {
 		if !call.complete() {
-			return false
+			calls = append(calls, call)
 		}
 	}
-	return true
+	for _, position := range c.sequences {
+		calls = append(calls, position.sequence.blockers(position.index)...)
+	}
+	return
+}
+
+func (c *mockedMethodCall) inSequence() bool {
+	return len(c.blockers()) == 0
+}
+
+// outOfSequence reports an error if args match this call, but it is waiting for other calls to complete
+func (c *mockedMethodCall) outOfSequence(args []interface{}) {
+	if !c.stubbedMethodCall.matches(args) || c.complete() {
+		return
+	}
+	if blockers := c.blockers(); len(blockers) > 0 {
+		descriptions := make([]string, len(blockers))
+		for i, blocker := range blockers {
+			ticks := make([]uint64, len(blocker.calls()))
+			for j, call := range blocker.calls() {
+				ticks[j] = call.tick
+			}
+			descriptions[i] = fmt.Sprintf("%v (called at ticks %v)", blocker, ticks)
+		}
+		c.t().Helper()
+		c.t().Errorf("%v was called with %v before\n  %s\ncompleted", c, args, strings.Join(descriptions, "\n  "))
+	}
 }
 
 func (c *mockedMethodCall) matches(args []interface{}) bool {
 	return c.stubbedMethodCall.matches(args) && !c.complete() && c.inSequence()
 }
 
-func (c *mockedMethodCall) spy(args []interface{}) ([]interface{}, error) {
-	c.count++
+// explain includes why args matching this call's matcher were not matched because of its expectations or sequencing
+func (c *mockedMethodCall) explain(args []interface{}) (bool, string) {
+	if matched, explanation := c.stubbedMethodCall.explain(args); !matched {
+		return false, explanation
+	}
+	if c.complete() {
+		return false, fmt.Sprintf("already completed %v calls", c.expect)
+	}
+	if blockers := c.blockers(); len(blockers) > 0 {
+		descriptions := make([]string, len(blockers))
+		for i, blocker := range blockers {
+			descriptions[i] = fmt.Sprint(blocker)
+		}
+		return false, fmt.Sprintf("waiting for\n  %s\nto complete", strings.Join(descriptions, "\n  "))
+	}
+	return true, ""
+}
+
+func (c *mockedMethodCall) spy(args []interface{}) ReturnValues {
+	c.recorded = append(c.recorded, c.newRecordedCall(args))
 	if c.trace() && c.complete() {
 		c.t().Helper()
-		c.t().Logf("%v completed expectations after %d calls", c, c.count)
+		c.t().Logf("%v completed expectations after %d calls", c, c.count())
 	}
 	return c.stubbedMethodCall.spy(args)
 }
@@ -126,8 +201,43 @@ func (c *mockedMethodCall) spy(args []interface{}) ([]interface{}, error) {
 func (c *mockedMethodCall) verify(t T) {
 	t.Helper()
 	if !c.met() {
-		t.Errorf("%v expected %v, found %d calls", c.stubbedMethodCall, c.expect, c.count)
+		t.Errorf("%v expected %v, found %s%s", c.stubbedMethodCall, c.expect, describeCalls(c.expect, c.receiver.started, c.recorded), c.explainMisses())
+	}
+	c.stubbedMethodCall.verify(t)
+}
+
+/*
+explainMisses explains the calls that were not matched by this unmet call
+
+For a call that wanted more calls, this is why the calls recorded elsewhere for the same method did not match.
+For a call without a matcher (eg the default Never() call for unexpected calls), this is why each of its recorded calls
+did not match the other calls registered for the method.
+*/
+func (c *mockedMethodCall) explainMisses() string {
+	sb := strings.Builder{}
+	if c.matcher == nil {
+		for _, call := range c.recorded {
+			if explanation := c.method.explainMismatches(call.args, c); explanation != "" {
+				fmt.Fprintf(&sb, "\n  tick %d: %v did not match:%s", call.tick, call.args, indent(explanation, "  "))
+			}
+		}
+		return sb.String()
+	}
+	if c.complete() || c.expect.Met(0) {
+		return ""
+	}
+	for _, other := range c.method.calls {
+		recorder, isRecorder := other.(interface{ calls() []*recordedCall })
+		if !isRecorder || other == MethodCall(c) {
+			continue
+		}
+		for _, call := range recorder.calls() {
+			if matched, explanation := Explain(c.matcher, call.args...); !matched {
+				fmt.Fprintf(&sb, "\n  tick %d: %v\n    %s", call.tick, call.args, indent(explanation, "    "))
+			}
+		}
 	}
+	return sb.String()
 }
 
 // ExpectInOrder is shorthand to Setup that the list of calls are expected to executed in this sequence
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// parkedCall is the state of an invocation parked until the test answers it, eg a GateCall or Request
+type parkedCall struct {
+	answered bool
+	answers  chan []interface{}
+}
+
+// parking publishes each invocation on a channel, then parks it until the test answers it or the timeout expires.
+// It is the common implementation of Gate and RequestChannel.
+type parking struct {
+	mutex     *sync.Mutex
+	t         T
+	method    reflect.Method
+	published reflect.Value //unbuffered channel of the parked calls, eg chan *GateCall
+	calls     string        //describes the published calls, eg "arrivals"
+	answered  string        //describes an answered call, eg "released"
+	count     int
+	timeout   time.Duration
+	sleeper   Timewarp
+}
+
+func newParking(published interface{}, calls string, answered string) parking {
+	return parking{
+		mutex:     &sync.Mutex{},
+		published: reflect.ValueOf(published),
+		calls:     calls,
+		answered:  answered,
+		timeout:   200 * time.Millisecond,
+		sleeper:   time.After,
+	}
+}
+
+func (p *parking) ForMethod(t T, method reflect.Method) {
+	p.t = t
+	p.method = method
+}
+
+func (p *parking) multiValued() bool { return true }
+
+func (p *parking) parks() bool { return true }
+
+// park publishes a new call, created by newCall (with the mutex held) from its number and state, then waits for
+// the call to be answered
+func (p *parking) park(newCall func(number int, state *parkedCall) fmt.Stringer) ([]interface{}, error) {
+	p.mutex.Lock()
+	p.count++
+	state := &parkedCall{answers: make(chan []interface{}, 1)}
+	call := newCall(p.count, state)
+	timeout := p.sleeper(p.timeout)
+	p.mutex.Unlock()
+
+	chosen, _, _ := reflect.Select([]reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: p.published, Send: reflect.ValueOf(call)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timeout)},
+	})
+	if chosen != 0 {
+		return nil, timeoutError(fmt.Sprintf("timed out waiting for %v to be received from %s", call, p.calls))
+	}
+
+	select {
+	case returns := <-state.answers:
+		return returns, nil
+	case <-timeout:
+		return nil, timeoutError(fmt.Sprintf("timed out waiting for %v to be %s", call, p.answered))
+	}
+}
+
+// await receives the next n published calls, failing the test fatally if they are not published before the timeout
+func (p *parking) await(n int) []reflect.Value {
+	if p.t != nil {
+		p.t.Helper()
+	}
+	calls := make([]reflect.Value, 0, n)
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: p.published},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(p.sleeper(p.timeout))},
+	}
+	for len(calls) < n {
+		chosen, call, _ := reflect.Select(cases)
+		if chosen != 0 {
+			p.fatalf("timed out waiting for %d %s, received %d", n, p.calls, len(calls))
+			return calls
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// answer sends returns to a parked call, failing the test fatally if it has already been answered
+func (p *parking) answer(call fmt.Stringer, state *parkedCall, returns []interface{}) {
+	p.mutex.Lock()
+	answered := state.answered
+	state.answered = true
+	p.mutex.Unlock()
+
+	if answered {
+		p.fatalf("%v has already been %s", call, p.answered)
+		return
+	}
+	state.answers <- returns
+}
+
+func (p *parking) fatalf(format string, args ...interface{}) {
+	if p.t == nil {
+		panic(fmt.Sprintf(format, args...))
+	}
+	p.t.Helper()
+	p.t.Fatalf(format, args...)
+}
+
+//Max time to wait for a call to be received or answered before failing the test
+func (p *parking) SetTimeout(timeout time.Duration, sleeper ...Timewarp) {
+	if len(sleeper) > 0 {
+		p.sleeper = sleeper[0]
+	}
+	p.timeout = timeout
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"time"
+)
+
+/*
+RequestChannel is ReturnValues that publish the arguments of each call as a Request, which the test answers with
+the values for that specific call. eg
+ rc := NewRequestChannel()
+ d.Stub("SomeQuery").Returning(rc)
+ go func() {
+   for req := range rc.Requests() {
+     req.Respond(Results{req.Args[0].(string)}, nil)
+   }
+ }()
+
+Calls that are not answered before the timeout fail the test, and are reported when the double is verified.
+*/
+type RequestChannel interface {
+
+	//Requests publishes each call waiting for values
+	Requests() <-chan *Request
+
+	//AwaitRequest waits for the next call, failing the test fatally if there is none before the timeout
+	AwaitRequest() *Request
+
+	//Set a timeout. If a call is not received from Requests, or is not answered, before the timeout expires
+	//the test will fail fatally
+	SetTimeout(timeout time.Duration, sleeper ...Timewarp)
+
+	//Verify fails the test for requests that were not answered
+	Verify(t T)
+
+	ArgsReturnValues
+}
+
+// A Request is a call waiting for values from a RequestChannel
+type Request struct {
+	//Args are the arguments of the call
+	Args []interface{}
+
+	//Number counts the requests on the channel from 1
+	Number int
+
+	rc *requestChannel
+	*parkedCall
+}
+
+func (r *Request) String() string {
+	return fmt.Sprintf("Request(%d)%v", r.Number, r.Args)
+}
+
+// Respond answers the request with returnValues, which must match the method's return types
+func (r *Request) Respond(returnValues ...interface{}) {
+	if r.rc.t != nil {
+		r.rc.t.Helper()
+		AssertMethodReturnValues(r.rc.t, r.rc.method, returnValues)
+	}
+	r.rc.answer(r, r.parkedCall, returnValues)
+}
+
+// NewRequestChannel creates a RequestChannel
+//
+// Use SetTimeout() to override the default timeout of 200 ms.
+func NewRequestChannel() RequestChannel {
+	requests := make(chan *Request)
+	return &requestChannel{parking: newParking(requests, "requests", "answered"), requests: requests}
+}
+
+type requestChannel struct {
+	parking
+	requests chan *Request
+	pending  []*Request //all requests, so unanswered requests can be reported by Verify
+}
+
+func (rc *requestChannel) Receive() ([]interface{}, error) {
+	return rc.ReceiveArgs(nil)
+}
+
+func (rc *requestChannel) ReceiveArgs(args []interface{}) ([]interface{}, error) {
+	return rc.park(func(number int, state *parkedCall) fmt.Stringer {
+		req := &Request{Args: args, Number: number, rc: rc, parkedCall: state}
+		rc.pending = append(rc.pending, req)
+		return req
+	})
+}
+
+func (rc *requestChannel) Requests() <-chan *Request {
+	return rc.requests
+}
+
+func (rc *requestChannel) AwaitRequest() *Request {
+	if rc.t != nil {
+		rc.t.Helper()
+	}
+	if requests := rc.await(1); len(requests) > 0 {
+		return requests[0].Interface().(*Request)
+	}
+	return nil
+}
+
+// Verify errors for each request that has not been answered
+func (rc *requestChannel) Verify(t T) {
+	t.Helper()
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	for _, req := range rc.pending {
+		if !req.answered {
+			t.Errorf("%v for %v was not answered", req, rc.method.Name)
+		}
+	}
+}
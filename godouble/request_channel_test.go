@@ -0,0 +1,204 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func respondWithLength(rc RequestChannel) {
+	for req := range rc.Requests() {
+		req.Respond(len(req.Args[0].(string)))
+	}
+}
+
+func TestRequestChannel_RespondsPerRequest(t *testing.T) {
+	d := newApiDouble(t)
+	defer d.Verify()
+
+	rc := NewRequestChannel()
+	d.Stub("call").Returning(rc)
+	go respondWithLength(rc)
+
+	for _, in := range []string{"abc", "hello", ""} {
+		if out := d.call(in); out != len(in) {
+			t.Errorf("Expected %d for %q, got %d", len(in), in, out)
+		}
+	}
+}
+
+func TestRequestChannel_AwaitRequest(t *testing.T) {
+	d := newApiDouble(t)
+	rc := NewRequestChannel()
+	d.Stub("test").Returning(rc)
+
+	results := make(chan int)
+	go func() {
+		r, _ := d.test(2, "x")
+		results <- r
+	}()
+
+	req := rc.AwaitRequest()
+	if expected := []interface{}{2, "x"}; !reflect.DeepEqual(req.Args, expected) || req.Number != 1 {
+		t.Errorf("Expected first request with args %v, got %v", expected, req)
+	}
+	req.Respond(4, nil)
+	if r := <-results; r != 4 {
+		t.Errorf("Expected 4, got %d", r)
+	}
+}
+
+func TestRequestChannel_InSequence(t *testing.T) {
+	d := newApiDouble(t)
+	rc := NewRequestChannel()
+	d.Stub("call").Returning(Returning(1).Then(rc))
+	go respondWithLength(rc)
+
+	if first, second := d.call("abc"), d.call("hello"); first != 1 || second != 5 {
+		t.Errorf("Expected 1 then 5, got %d, %d", first, second)
+	}
+}
+
+func TestRequestChannel_ArgsThroughWrappers(t *testing.T) {
+	noDelay := func(d time.Duration) <-chan time.Time {
+		c := make(chan time.Time, 1)
+		c <- time.Now()
+		return c
+	}
+
+	for _, test := range []struct {
+		name string
+		rv   func(rc RequestChannel) ReturnValues
+	}{
+		{"Times", func(rc RequestChannel) ReturnValues { return Times(2, rc) }},
+		{"Cycle", func(rc RequestChannel) ReturnValues { return Cycle(rc) }},
+		{"ThenForever", func(rc RequestChannel) ReturnValues { return Returning(1).ThenForever(rc) }},
+		{"Delayed", func(rc RequestChannel) ReturnValues { return Delayed(rc, time.Second, noDelay) }},
+		{"DelayedBy", func(rc RequestChannel) ReturnValues { return DelayedBy(rc, UniformLatency(time.Second, Seed(1)), noDelay) }},
+		{"FailNth", func(rc RequestChannel) ReturnValues { return FailNth(3, Values(0), rc) }},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			d := newApiDouble(t)
+			defer d.Verify()
+
+			rc := NewRequestChannel()
+			d.Stub("call").Returning(test.rv(rc))
+			go respondWithLength(rc)
+
+			if out := d.call("hello"); out != 5 && out != 1 {
+				t.Errorf("Expected length of hello, got %d", out)
+			}
+			if out := d.call("abc"); out != 3 {
+				t.Errorf("Expected length of abc, got %d", out)
+			}
+		})
+	}
+}
+
+func TestRequestChannel_VerifyThroughWrappers(t *testing.T) {
+	immediately := func(d time.Duration) <-chan time.Time {
+		c := make(chan time.Time, 1)
+		c <- time.Now()
+		return c
+	}
+
+	doubleT := NewTDouble(t)
+	spy := doubleT.Spy("Errorf")
+
+	d := newApiDouble(doubleT)
+	rc := NewRequestChannel()
+	times := Times(1, rc)
+	d.Stub("call").Returning(times)
+	rc.SetTimeout(time.Second, immediately)
+
+	if _, err := times.(ArgsReturnValues).ReceiveArgs([]interface{}{"x"}); err == nil {
+		t.Errorf("Expected error for request that was not received")
+	}
+	d.Verify()
+
+	spy.Matching(printfMatcher(`^Request\(1\)\[x\] for call was not answered$`)).Expect(Once())
+}
+
+func TestRequestChannel_VerifyErrorsForUnansweredRequests(t *testing.T) {
+	immediately := func(d time.Duration) <-chan time.Time {
+		c := make(chan time.Time, 1)
+		c <- time.Now()
+		return c
+	}
+
+	doubleT := NewTDouble(t)
+	spy := doubleT.Spy("Errorf")
+
+	d := newApiDouble(doubleT)
+	rc := NewRequestChannel()
+	d.Stub("call").Returning(rc)
+	rc.SetTimeout(time.Second, immediately)
+
+	if _, err := rc.ReceiveArgs([]interface{}{"x"}); err == nil {
+		t.Errorf("Expected error for request that was not received")
+	}
+	d.Verify()
+
+	spy.Matching(printfMatcher(`^Request\(1\)\[x\] for call was not answered$`)).Expect(Once())
+}
+
+func TestRequestChannel_FatallyFailsTheTest(t *testing.T) {
+	testMethod, _ := reflect.TypeOf((*api)(nil)).Elem().MethodByName("test")
+
+	type test struct {
+		name        string
+		rc          func(rc RequestChannel)
+		expectedMsg string
+	}
+
+	tests := []test{
+		{"AwaitRequest", func(rc RequestChannel) {
+			rc.SetTimeout(time.Millisecond)
+			rc.AwaitRequest()
+		}, `timed out waiting for 1 requests`},
+		{"RespondValues", func(rc RequestChannel) {
+			go rc.ReceiveArgs([]interface{}{1, "x"})
+			rc.AwaitRequest().Respond("not an int", nil)
+		}, `string`},
+		{"AlreadyAnswered", func(rc RequestChannel) {
+			go rc.ReceiveArgs([]interface{}{1, "x"})
+			req := rc.AwaitRequest()
+			req.Respond(1, nil)
+			req.Respond(1, nil)
+		}, `Request\(1\)\[1 x\] has already been answered`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			rc := NewRequestChannel()
+			NewReturnsForMethod(tDouble, testMethod, rc)
+			test.rc(rc)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
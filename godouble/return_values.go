@@ -18,6 +18,7 @@ package godouble
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
 	"reflect"
 	"sync"
@@ -29,6 +30,9 @@ type ReturnValues interface {
 
 	//Receive is called when a method is exercised
 	//
+	// Calls to Receive are serialised for each method, except for ReturnValues that park calls until the test
+	// answers them (eg Gate, RequestChannel), which are received concurrently.
+	//
 	// non nil error response will fatally terminate the test
 	Receive() ([]interface{}, error)
 }
@@ -38,11 +42,90 @@ type ValidatingReturnValues interface {
 	ForMethod(t T, method reflect.Method)
 }
 
+// ArgsReturnValues are ReturnValues that can respond to the arguments of each call, eg a RequestChannel
+type ArgsReturnValues interface {
+	ReturnValues
+	ReceiveArgs(args []interface{}) ([]interface{}, error)
+}
+
+// VerifiableReturnValues are ReturnValues that are verified when the double they were provided to is verified
+type VerifiableReturnValues interface {
+	ReturnValues
+	Verify(t T)
+}
+
+// receiveArgs receives values from rv, with args if it is an ArgsReturnValues
+func receiveArgs(rv ReturnValues, args []interface{}) ([]interface{}, error) {
+	if argsRV, hasArgs := rv.(ArgsReturnValues); hasArgs {
+		return argsRV.ReceiveArgs(args)
+	}
+	return rv.Receive()
+}
+
+// verifyAll verifies each of values that is VerifiableReturnValues
+func verifyAll(t T, values ...ReturnValues) {
+	t.Helper()
+	for _, rv := range values {
+		if verifiableRV, isVerifiable := rv.(VerifiableReturnValues); isVerifiable {
+			verifiableRV.Verify(t)
+		}
+	}
+}
+
+// boundReturnValues receives values for the arguments of a specific call
+type boundReturnValues struct {
+	ArgsReturnValues
+	args []interface{}
+}
+
+func (b boundReturnValues) Receive() ([]interface{}, error) {
+	return b.ReceiveArgs(b.args)
+}
+
+func (b boundReturnValues) parks() bool { return parks(b.ArgsReturnValues) }
+
+// timeoutError is returned by ReturnValues that timed out waiting for the test to provide values
+type timeoutError string
+
+func (e timeoutError) Error() string { return string(e) }
+
+func (e timeoutError) Timeout() bool { return true }
+
+// isTimeout is whether err, or an error it wraps, is a timeout
+func isTimeout(err error) bool {
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
+
 type multiValues interface {
 	ReturnValues
 	multiValued() bool
 }
 
+// parkingValues are ReturnValues that can park a call until the test answers it, eg a Gate
+//
+// They are received outside the method mutex so that other calls can arrive while one is parked.
+type parkingValues interface {
+	ReturnValues
+	parks() bool
+}
+
+// parks is whether rv, or a ReturnValues it wraps, can park a call
+func parks(rv ReturnValues) bool {
+	p, isParking := rv.(parkingValues)
+	return isParking && p.parks()
+}
+
+// anyParks is whether any of values can park a call
+func anyParks(values []ReturnValues) bool {
+	for _, rv := range values {
+		if parks(rv) {
+			return true
+		}
+	}
+	return false
+}
+
 // A Timewarp can be used to simulate a sleep, eg when testing using a fake clock.
 // The canonical sleeper is
 //   time.After
@@ -168,7 +251,7 @@ func (rc *returnChannel) Receive() (returns []interface{}, err error) {
 			err = errors.New("requested values from closed return channel")
 		}
 	case _ = <-rc.sleeper(rc.timeout):
-		err = errors.New("timed out waiting for return channel to provide values")
+		err = timeoutError("timed out waiting for return channel to provide values")
 	}
 
 	return
@@ -208,9 +291,20 @@ func newDelayedReturnValues(rv ReturnValues, f func() time.Duration, sleeper ...
 }
 
 func (d *delayedReturnValues) Receive() ([]interface{}, error) {
+	return d.ReceiveArgs(nil)
+}
+
+func (d *delayedReturnValues) ReceiveArgs(args []interface{}) ([]interface{}, error) {
 	//Simulate IO delay / long poll etc
 	<-d.sleeper(d.delayer())
-	return d.ReturnValues.Receive()
+	return receiveArgs(d.ReturnValues, args)
+}
+
+func (d *delayedReturnValues) parks() bool { return parks(d.ReturnValues) }
+
+func (d *delayedReturnValues) Verify(t T) {
+	t.Helper()
+	verifyAll(t, d.ReturnValues)
 }
 
 func (d delayedReturnValues) ForMethod(t T, method reflect.Method) {
@@ -235,26 +329,61 @@ func RandDelayed(rv ReturnValues, max time.Duration, sleep ...Timewarp) ReturnVa
 }
 
 type sequentialReturnValues struct {
+	mutex  *sync.Mutex
 	values []ReturnValues
-	rvChan <-chan []interface{}
-	once   *sync.Once
+	next   int //index of the current member of values
 }
 
-func (s *sequentialReturnValues) Receive() (returns []interface{}, err error) {
-	s.once.Do(s.run)
-	if generatedReturns, ok := <-s.rvChan; ok {
-		returns = generatedReturns
-	} else {
-		err = errors.New("no available values")
+/*
+Receive returns values from the current member of the sequence.
+
+A member that is not multi valued provides a single value. A multi valued member (eg a ReturnChannel, Times)
+provides values until it returns an error.
+*/
+func (s *sequentialReturnValues) Receive() ([]interface{}, error) {
+	return s.ReceiveArgs(nil)
+}
+
+// ReceiveArgs receives values from the current member of the sequence, passing args to ArgsReturnValues
+//
+// The member is received from outside the lock so that a member that blocks (eg a Gate) does not hold up other callers.
+func (s *sequentialReturnValues) ReceiveArgs(args []interface{}) ([]interface{}, error) {
+	for {
+		s.mutex.Lock()
+		current := s.next
+		if current >= len(s.values) {
+			s.mutex.Unlock()
+			return nil, errors.New("no available values")
+		}
+		rv := s.values[current]
+		mv, isMultiValue := rv.(multiValues)
+		multiValued := isMultiValue && mv.multiValued()
+		if !multiValued {
+			s.next++
+		}
+		s.mutex.Unlock()
+
+		result, err := receiveArgs(rv, args)
+		if err == nil {
+			return result, nil
+		}
+		if multiValued {
+			s.mutex.Lock()
+			if s.next == current {
+				s.next++
+			}
+			s.mutex.Unlock()
+		}
 	}
-	return
 }
 
 func (s *sequentialReturnValues) multiValued() bool { return true }
 
+func (s *sequentialReturnValues) parks() bool { return anyParks(s.values) }
+
 //Sequence returns values from each of 'values' until there are no further values available
 func Sequence(values ...ReturnValues) ReturnValues {
-	return &sequentialReturnValues{values: values, once: &sync.Once{}}
+	return &sequentialReturnValues{mutex: &sync.Mutex{}, values: values}
 }
 
 func (s *sequentialReturnValues) ForMethod(t T, m reflect.Method) {
@@ -265,24 +394,173 @@ func (s *sequentialReturnValues) ForMethod(t T, m reflect.Method) {
 	}
 }
 
-func (s *sequentialReturnValues) run() {
-	rvChan := make(chan []interface{})
-	s.rvChan = rvChan
-	go func(s *sequentialReturnValues) {
-		for _, rv := range s.values {
-			if mv, isMultiValue := rv.(multiValues); isMultiValue && mv.multiValued() {
-				for {
-					if result, err := mv.Receive(); err != nil {
-						break
-					} else {
-						rvChan <- result
-					}
-				}
-			} else if result, err := rv.Receive(); err == nil {
-				rvChan <- result
-			}
+func (s *sequentialReturnValues) Verify(t T) {
+	t.Helper()
+	verifyAll(t, s.values...)
+}
+
+// ChainedReturnValues is a Sequence built fluently via Returning
+type ChainedReturnValues interface {
+	ReturnValues
+
+	// Then appends values to be returned once, or a ReturnValues (eg Times) to the sequence
+	Then(values ...interface{}) ChainedReturnValues
+
+	// ThenForever appends values, or a ReturnValues, to be returned for all subsequent calls
+	ThenForever(values ...interface{}) ReturnValues
+}
+
+// toReturnValues is values as a single ReturnValues, else as fixed Values
+func toReturnValues(values []interface{}) ReturnValues {
+	if len(values) == 1 {
+		if rv, isRv := values[0].(ReturnValues); isRv {
+			return rv
 		}
-		close(rvChan)
+	}
+	return Values(values...)
+}
+
+/*
+Returning starts a sequence of return values, with values returned once (or a ReturnValues eg Times)
+
+eg to return 1, then 2 twice, then 3 for all subsequent calls
+ Returning(1).Then(Times(2, Values(2))).ThenForever(3)
+*/
+func Returning(values ...interface{}) ChainedReturnValues {
+	return &sequentialReturnValues{mutex: &sync.Mutex{}, values: []ReturnValues{toReturnValues(values)}}
+}
+
+func (s *sequentialReturnValues) Then(values ...interface{}) ChainedReturnValues {
+	s.values = append(s.values, toReturnValues(values))
+	return s
+}
+
+func (s *sequentialReturnValues) ThenForever(values ...interface{}) ReturnValues {
+	s.values = append(s.values, foreverReturnValues{toReturnValues(values)})
+	return s
+}
+
+// foreverReturnValues is multi valued, never moving on to the next member of a sequence
+type foreverReturnValues struct {
+	ReturnValues
+}
+
+func (f foreverReturnValues) ReceiveArgs(args []interface{}) ([]interface{}, error) {
+	return receiveArgs(f.ReturnValues, args)
+}
+
+func (f foreverReturnValues) Verify(t T) {
+	t.Helper()
+	verifyAll(t, f.ReturnValues)
+}
+
+func (f foreverReturnValues) multiValued() bool { return true }
+
+func (f foreverReturnValues) parks() bool { return parks(f.ReturnValues) }
+
+func (f foreverReturnValues) ForMethod(t T, m reflect.Method) {
+	if validatingRV, isValidating := f.ReturnValues.(ValidatingReturnValues); isValidating {
+		validatingRV.ForMethod(t, m)
+	}
+}
+
+type timesReturnValues struct {
+	mutex *sync.Mutex
+	times int
+	count int
+	ReturnValues
+}
+
+func (tv *timesReturnValues) Receive() ([]interface{}, error) {
+	return tv.ReceiveArgs(nil)
+}
+
+func (tv *timesReturnValues) ReceiveArgs(args []interface{}) ([]interface{}, error) {
+	tv.mutex.Lock()
+	if tv.count >= tv.times {
+		tv.mutex.Unlock()
+		return nil, fmt.Errorf("no available values after %d times", tv.times)
+	}
+	tv.count++
+	tv.mutex.Unlock()
+	return receiveArgs(tv.ReturnValues, args)
+}
 
-	}(s)
+func (tv *timesReturnValues) Verify(t T) {
+	t.Helper()
+	verifyAll(t, tv.ReturnValues)
+}
+
+func (tv *timesReturnValues) multiValued() bool { return true }
+
+func (tv *timesReturnValues) parks() bool { return parks(tv.ReturnValues) }
+
+func (tv *timesReturnValues) ForMethod(t T, m reflect.Method) {
+	if validatingRV, isValidating := tv.ReturnValues.(ValidatingReturnValues); isValidating {
+		validatingRV.ForMethod(t, m)
+	}
+}
+
+// Times returns values from rv for only n invocations, eg as a member of a Sequence
+func Times(n int, rv ReturnValues) ReturnValues {
+	return &timesReturnValues{mutex: &sync.Mutex{}, times: n, ReturnValues: rv}
+}
+
+type cyclicReturnValues struct {
+	mutex  *sync.Mutex
+	values []ReturnValues
+	next   int
+	err    error //the cycle ended when a member was exhausted
+}
+
+func (c *cyclicReturnValues) Receive() ([]interface{}, error) {
+	return c.ReceiveArgs(nil)
+}
+
+func (c *cyclicReturnValues) ReceiveArgs(args []interface{}) ([]interface{}, error) {
+	c.mutex.Lock()
+	if len(c.values) == 0 {
+		c.mutex.Unlock()
+		return nil, errors.New("no values to cycle")
+	}
+	if c.err != nil {
+		c.mutex.Unlock()
+		return nil, c.err
+	}
+	rv := c.values[c.next]
+	c.next = (c.next + 1) % len(c.values)
+	c.mutex.Unlock()
+
+	returns, err := receiveArgs(rv, args)
+	if err != nil {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		c.err = fmt.Errorf("cycle ended: %w", err)
+		return nil, c.err
+	}
+	return returns, nil
+}
+
+func (c *cyclicReturnValues) Verify(t T) {
+	t.Helper()
+	verifyAll(t, c.values...)
+}
+
+func (c *cyclicReturnValues) multiValued() bool { return true }
+
+func (c *cyclicReturnValues) parks() bool { return anyParks(c.values) }
+
+func (c *cyclicReturnValues) ForMethod(t T, m reflect.Method) {
+	for _, rv := range c.values {
+		if validatingRV, isValidating := rv.(ValidatingReturnValues); isValidating {
+			validatingRV.ForMethod(t, m)
+		}
+	}
+}
+
+// Cycle returns a single value from each of values in turn, starting again from the first after the last
+//
+// The cycle ends when any of values is exhausted (eg Times), with every subsequent invocation returning an error.
+func Cycle(values ...ReturnValues) ReturnValues {
+	return &cyclicReturnValues{mutex: &sync.Mutex{}, values: values}
 }
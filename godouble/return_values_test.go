@@ -20,6 +20,9 @@ import (
 	"errors"
 	"reflect"
 	"regexp"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -91,6 +94,40 @@ func TestReturnValues_FatallyFailsTheTest(t *testing.T) {
 	}
 }
 
+// overlappingReturnValues records whether calls to Receive overlap
+type overlappingReturnValues struct {
+	active     int32
+	overlapped int32
+}
+
+func (o *overlappingReturnValues) Receive() ([]interface{}, error) {
+	if atomic.AddInt32(&o.active, 1) > 1 {
+		atomic.StoreInt32(&o.overlapped, 1)
+	}
+	defer atomic.AddInt32(&o.active, -1)
+	time.Sleep(time.Millisecond)
+	return []interface{}{1}, nil
+}
+
+func TestReturnValues_ReceivedOneAtATime(t *testing.T) {
+	d := newApiDouble(t, func(c *TestDouble) { c.DisableTrace() })
+	rv := &overlappingReturnValues{}
+	d.Stub("call").Returning(rv)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.call("concurrent")
+		}()
+	}
+	wg.Wait()
+	if atomic.LoadInt32(&rv.overlapped) != 0 {
+		t.Errorf("Expected Receive to be called one at a time")
+	}
+}
+
 func TestDelayed(t *testing.T) {
 	apiCallMethod, _ := reflect.TypeOf((*api)(nil)).Elem().MethodByName("call")
 
@@ -255,6 +292,75 @@ func TestSequence(t *testing.T) {
 	}
 }
 
+func TestSequence_DoesNotStartGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	seq := Sequence(Values(1), Values(2), Values(3))
+	if _, err := seq.Receive(); err != nil {
+		t.Errorf("Expected value, got %v", err)
+	}
+	if after := runtime.NumGoroutine(); after != before {
+		t.Errorf("Expected %d goroutines, got %d", before, after)
+	}
+}
+
+func TestFiniteAndCyclicReturnValues(t *testing.T) {
+	type test struct {
+		name      string
+		values    ReturnValues
+		expected  []int
+		exhausted bool
+	}
+	apiCallMethod, _ := reflect.TypeOf((*api)(nil)).Elem().MethodByName("call")
+
+	tests := []test{
+		{"Times", Times(2, Values(1)), []int{1, 1}, true},
+		{"TimesZero", Times(0, Values(1)), []int{}, true},
+		{"SequenceOfTimes", Sequence(Times(2, Values(1)), Values(2), Times(1, Values(3))), []int{1, 1, 2, 3}, true},
+		{"Cycle", Cycle(Values(1), Values(2), Values(3)), []int{1, 2, 3, 1, 2, 3, 1}, false},
+		{"CycleOfTimes", Cycle(Times(2, Values(1)), Values(2)), []int{1, 2, 1, 2}, true},
+		{"CycleOfTimesEndsAtFirstExhausted", Cycle(Values(1), Times(1, Values(2)), Values(3)), []int{1, 2, 3, 1}, true},
+		{"EmptyCycle", Cycle(), []int{}, true},
+		{"Returning", Returning(1), []int{1}, true},
+		{"ReturningThen", Returning(1).Then(2).Then(Times(2, Values(3))), []int{1, 2, 3, 3}, true},
+		{"ThenForever", Returning(1).Then(2).ThenForever(3), []int{1, 2, 3, 3, 3, 3}, false},
+		{"ThenForeverCycle", Returning(1).ThenForever(Cycle(Values(2), Values(3))), []int{1, 2, 3, 2, 3}, false},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			NewReturnsForMethod(t, apiCallMethod, test.values)
+			for _, ex := range test.expected {
+				rcv, err := test.values.Receive()
+				if err != nil || len(rcv) != 1 {
+					t.Errorf("Expected [1]int, nil got %v,%v", rcv, err)
+				} else if actual := rcv[0].(int); actual != ex {
+					t.Errorf("expected %d, got %d", ex, actual)
+				}
+			}
+			//Exhausted values stay exhausted
+			for i := 0; i < 3; i++ {
+				if _, err := test.values.Receive(); test.exhausted != (err != nil) {
+					t.Errorf("Expected exhausted %v after %d values, got error %v", test.exhausted, len(test.expected)+i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestReturning_ValidatesForMethod(t *testing.T) {
+	apiCallMethod, _ := reflect.TypeOf((*api)(nil)).Elem().MethodByName("call")
+	tDouble := NewTDouble(t)
+	spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+	defer func(spy FakeMethodCall) {
+		recover()
+		spy.Expect(Once())
+	}(spy)
+
+	NewReturnsForMethod(tDouble, apiCallMethod, Returning(1).Then(Times(2, Values("x"))))
+	t.Errorf("Expect unreachable")
+}
+
 func TestReturnChannel_SendFatallyFailsTheTest(t *testing.T) {
 	apiCallMethod, _ := reflect.TypeOf((*api)(nil)).Elem().MethodByName("call")
 	type test struct {
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+A CallSequence is a reusable group of MockedMethodCalls, possibly on different doubles, that are expected to be
+called either InOrder or in AnyOrder.
+
+Groups can be nested, eg to setup A then (B and C in any order) then D
+
+ seq := InOrder(a)
+ seq.AnyOrder(b, c)
+ d.InSequence(seq)
+
+A mock in an ordered group will only match once all the calls in preceding members of the group are complete.
+As with After, mocks only complete if their Expectation is a Completion (eg Once(), Exactly(n)).
+
+A matching call that is out of sequence is reported as an error before falling through to the default call.
+*/
+type CallSequence struct {
+	parent  *CallSequence
+	index   int
+	ordered bool
+	members []sequenceMember
+}
+
+type sequenceMember interface {
+	complete() bool
+	incomplete() []MockedMethodCall
+}
+
+type sequencePosition struct {
+	sequence *CallSequence
+	index    int
+}
+
+// InOrder returns a new CallSequence where each member is expected to complete before the next is called
+func InOrder(calls ...MockedMethodCall) *CallSequence {
+	return newCallSequence(nil, true, calls)
+}
+
+// AnyOrder returns a new CallSequence whose members can be called in any order
+func AnyOrder(calls ...MockedMethodCall) *CallSequence {
+	return newCallSequence(nil, false, calls)
+}
+
+func newCallSequence(parent *CallSequence, ordered bool, calls []MockedMethodCall) *CallSequence {
+	seq := &CallSequence{parent: parent, ordered: ordered}
+	if parent != nil {
+		seq.index = parent.add(seq)
+	}
+	for _, call := range calls {
+		call.InSequence(seq)
+	}
+	return seq
+}
+
+// InOrder adds and returns a nested ordered group as the next member of s
+func (s *CallSequence) InOrder(calls ...MockedMethodCall) *CallSequence {
+	return newCallSequence(s, true, calls)
+}
+
+// AnyOrder adds and returns a nested unordered group as the next member of s
+func (s *CallSequence) AnyOrder(calls ...MockedMethodCall) *CallSequence {
+	return newCallSequence(s, false, calls)
+}
+
+func (s *CallSequence) add(member sequenceMember) int {
+	s.members = append(s.members, member)
+	return len(s.members) - 1
+}
+
+func (s *CallSequence) complete() bool {
+	for _, member := range s.members {
+		if !member.complete() {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *CallSequence) incomplete() (calls []MockedMethodCall) {
+	for _, member := range s.members {
+		calls = append(calls, member.incomplete()...)
+	}
+	return
+}
+
+// blockers returns the incomplete calls that must complete before the member at index can be called
+func (s *CallSequence) blockers(index int) (calls []MockedMethodCall) {
+	if s.parent != nil {
+		calls = s.parent.blockers(s.index)
+	}
+	if s.ordered {
+		for _, member := range s.members[:index] {
+			calls = append(calls, member.incomplete()...)
+		}
+	}
+	return
+}
+
+func (s *CallSequence) String() string {
+	members := make([]string, len(s.members))
+	for i, member := range s.members {
+		members[i] = fmt.Sprint(member)
+	}
+	name := "AnyOrder"
+	if s.ordered {
+		name = "InOrder"
+	}
+	return fmt.Sprintf("%s{%s}", name, strings.Join(members, ","))
+}
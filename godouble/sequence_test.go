@@ -0,0 +1,116 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"testing"
+)
+
+func TestCallSequence_NestedAnyOrder(t *testing.T) {
+	type test struct {
+		name  string
+		order []string
+	}
+
+	tests := []test{
+		{"BThenC", []string{"a", "b", "c", "d"}},
+		{"CThenB", []string{"a", "c", "b", "d"}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			d1 := newApiDouble(t)
+			d2 := newApiDouble(t)
+			defer Verify(d1, d2)
+
+			a := d1.Mock("call").Matching("a").Returning(1).Expect(Once())
+			b := d2.Mock("call").Matching("b").Returning(2).Expect(Once())
+			c := d1.Mock("call").Matching("c").Returning(3).Expect(Once())
+			d := d2.Mock("call").Matching("d").Returning(4).Expect(Once())
+
+			seq := InOrder(a)
+			seq.AnyOrder(b, c)
+			d.InSequence(seq)
+			assertMatch(t, seq, `InOrder\{.*a.*,AnyOrder\{.*b.*,.*c.*\},.*d.*\}`)
+
+			results := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}
+			for _, s := range test.order {
+				var r int
+				if s == "b" || s == "d" {
+					r = d2.call(s)
+				} else {
+					r = d1.call(s)
+				}
+				if r != results[s] {
+					t.Errorf("Expected call(%s) to return %d, got %d", s, results[s], r)
+				}
+			}
+		})
+	}
+}
+
+func TestCallSequence_NestedInOrder(t *testing.T) {
+	d1 := newApiDouble(t)
+	defer d1.Verify()
+
+	a := d1.Mock("call").Matching("a").Returning(1).Expect(Once())
+	b := d1.Mock("call").Matching("b").Returning(2).Expect(Once())
+	c := d1.Mock("call").Matching("c").Returning(3).Expect(Once())
+	x := d1.Mock("call").Matching("x").Returning(4).Expect(Twice())
+
+	//(a then b) and x in any order, then c
+	seq := InOrder()
+	group := seq.AnyOrder(x)
+	group.InOrder(a, b)
+	seq.InOrder(c)
+
+	for _, s := range []string{"x", "a", "x", "b", "c"} {
+		if r := d1.call(s); r == 0 {
+			t.Errorf("Expected call(%s) to match a mock in sequence", s)
+		}
+	}
+}
+
+func TestCallSequence_ReportsOutOfSequenceCalls(t *testing.T) {
+	doubleT := NewTDouble(t, func(c *TestDouble) {
+		//c.EnableTrace()
+	})
+	spy := doubleT.Spy("Errorf")
+
+	d1 := newApiDouble(doubleT)
+	a := d1.Mock("call").Matching("a").Returning(1).Expect(Once())
+	b := d1.Mock("call").Matching("b").Returning(2).Expect(Once())
+	c := d1.Mock("call").Matching("c").Returning(3).Expect(Once())
+	seq := InOrder(a)
+	seq.AnyOrder(b, c)
+	d1.Mock("other").Returning(4).Expect(Once()).InSequence(seq)
+
+	d1.call("a")
+	d1.call("b")
+	if r := d1.other(); r != 0 {
+		t.Errorf("Expected out of sequence call to fall through to the default, got %d", r)
+	}
+	spy.Matching(printfMatcher(`(?s)other was called with \[\] before.*call matching.*c.*\(called at ticks \[\]\)\s+completed`)).Expect(Once())
+
+	d1.call("c")
+	if r := d1.other(); r != 4 {
+		t.Errorf("Expected call in sequence to return 4, got %d", r)
+	}
+
+	spy.Expect(Once())
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"reflect"
+	"sync"
+)
+
+var registeredDoubles = struct {
+	mutex *sync.Mutex
+	wraps map[reflect.Type]func(*TestDouble) interface{}
+}{&sync.Mutex{}, map[reflect.Type]func(*TestDouble) interface{}{}}
+
+/*
+RegisterDouble registers how to wrap a TestDouble for forInterface in an implementation of that interface, so
+SmartDefaults can return a child double for methods that return forInterface.
+
+forInterface is expected to be the nil implementation of an interface - (*Iface)(nil).
+
+Doubles generated by doublegen register themselves. eg for a manually created double
+ RegisterDouble((*API)(nil), func(d *TestDouble) interface{} { return &APIDouble{TestDouble: d} })
+*/
+func RegisterDouble(forInterface interface{}, wrap func(*TestDouble) interface{}) {
+	registeredDoubles.mutex.Lock()
+	defer registeredDoubles.mutex.Unlock()
+	registeredDoubles.wraps[reflect.TypeOf(forInterface).Elem()] = wrap
+}
+
+func registeredDouble(forInterface reflect.Type) (wrap func(*TestDouble) interface{}, found bool) {
+	registeredDoubles.mutex.Lock()
+	defer registeredDoubles.mutex.Unlock()
+	wrap, found = registeredDoubles.wraps[forInterface]
+	return
+}
+
+/*
+SmartDefaults is a TestDouble configurator for default return values that are safe to use
+
+ Slices and maps are empty and non nil
+ Channels are closed
+ Pointers to structs point to a zeroed struct
+ Interfaces with a registered double (see RegisterDouble) are a child TestDouble. See TestDouble.Child
+ Other values are zeroed
+*/
+func SmartDefaults(d *TestDouble) {
+	d.SetDefaultReturnValues(func(m Method) ReturnValues {
+		return smartReturnValues{d, m.Reflect()}
+	})
+}
+
+type smartReturnValues struct {
+	receiver *TestDouble
+	m        reflect.Method
+}
+
+func (s smartReturnValues) Receive() ([]interface{}, error) {
+	child := s.receiver.child(s.m)
+	results := make([]interface{}, s.m.Type.NumOut())
+	for i := range results {
+		out := s.m.Type.Out(i)
+		if child != nil && out == child.double.forInterface {
+			results[i] = child.impl
+		} else {
+			results[i] = smartValue(out).Interface()
+		}
+	}
+	return results, nil
+}
+
+// smartValue generates a new value each time, so callers do not share mutable slices and maps
+func smartValue(t reflect.Type) reflect.Value {
+	switch t.Kind() {
+	case reflect.Slice:
+		return reflect.MakeSlice(t, 0, 0)
+	case reflect.Map:
+		return reflect.MakeMap(t)
+	case reflect.Chan:
+		//Only a bidirectional channel can be closed
+		c := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, t.Elem()), 0)
+		c.Close()
+		return c.Convert(t)
+	case reflect.Ptr:
+		if t.Elem().Kind() == reflect.Struct {
+			return reflect.New(t.Elem())
+		}
+	}
+	return reflect.Zero(t)
+}
+
+type childDouble struct {
+	double *TestDouble
+	impl   interface{}
+}
+
+// child returns the child double for the first result of m that is an interface with a registered double,
+// creating it with the same configurators as d, or nil if there is no such result
+func (d *TestDouble) child(m reflect.Method) *childDouble {
+	d.childMutex.Lock()
+	defer d.childMutex.Unlock()
+	if child, found := d.children[m.Name]; found {
+		return child
+	}
+
+	for i := 0; i < m.Type.NumOut(); i++ {
+		out := m.Type.Out(i)
+		if out.Kind() != reflect.Interface {
+			continue
+		}
+		if wrap, found := registeredDouble(out); found {
+			double := NewDouble(d.t, reflect.Zero(reflect.PtrTo(out)).Interface(), d.configurators...)
+			impl := wrap(double)
+			if !reflect.TypeOf(impl).Implements(out) {
+				d.t.Fatalf("Registered double %T for %v.%s does not implement %v", impl, d, m.Name, out)
+			}
+			child := &childDouble{double: double, impl: impl}
+			d.children[m.Name] = child
+			return child
+		}
+	}
+	return nil
+}
+
+/*
+Child returns the child TestDouble returned by methodName, creating it if necessary, so that it can be configured.
+
+The method must return an interface with a registered double (see RegisterDouble). With SmartDefaults, the child is
+returned from calls to methodName that do not provide their own ReturnValues. eg
+ d := NewStoreProviderDouble(t, SmartDefaults)
+ d.Stub("GetStore")
+ d.Child("GetStore").Stub("Get").Returning("value", nil)
+ d.GetStore().Get("key") // "value", nil
+
+The child is created with the same configurators as d and is verified when d is verified.
+*/
+func (d *TestDouble) Child(methodName string) (child *TestDouble) {
+	d.t.Helper()
+	if m, found := d.methods[methodName]; found {
+		if c := d.child(m.m); c != nil {
+			child = c.double
+		} else {
+			d.t.Fatalf("Cannot get Child for method %s of %v that does not return an interface with a registered double", methodName, d)
+		}
+	} else {
+		d.t.Fatalf("Cannot get Child for non existent method %s for %v", methodName, d)
+	}
+	return
+}
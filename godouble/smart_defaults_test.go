@@ -0,0 +1,202 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"testing"
+)
+
+type store interface {
+	get(key string) (string, error)
+	keys() []string
+}
+
+type storeDouble struct {
+	store
+	*TestDouble
+}
+
+func (d *storeDouble) get(key string) (r string, e error) {
+	d.TestDouble.T().Helper()
+	returns := d.Invoke("get", key)
+	r, _ = returns[0].(string)
+	e, _ = returns[1].(error)
+	return
+}
+
+func (d *storeDouble) keys() (r []string) {
+	d.TestDouble.T().Helper()
+	r, _ = d.Invoke("keys")[0].([]string)
+	return
+}
+
+type storeProvider interface {
+	store(name string) store
+	items() []string
+	limits() map[string]int
+	events() <-chan string
+	account() *testAccount
+	count() int
+	err() error
+}
+
+type storeProviderDouble struct {
+	storeProvider
+	*TestDouble
+}
+
+func newStoreProviderDouble(t T, configurators ...func(*TestDouble)) *storeProviderDouble {
+	return &storeProviderDouble{TestDouble: NewDouble(t, (*storeProvider)(nil), configurators...)}
+}
+
+func (d *storeProviderDouble) store(name string) (r store) {
+	d.TestDouble.T().Helper()
+	r, _ = d.Invoke("store", name)[0].(store)
+	return
+}
+
+func (d *storeProviderDouble) items() (r []string) {
+	d.TestDouble.T().Helper()
+	r, _ = d.Invoke("items")[0].([]string)
+	return
+}
+
+func (d *storeProviderDouble) limits() (r map[string]int) {
+	d.TestDouble.T().Helper()
+	r, _ = d.Invoke("limits")[0].(map[string]int)
+	return
+}
+
+func (d *storeProviderDouble) events() (r <-chan string) {
+	d.TestDouble.T().Helper()
+	r, _ = d.Invoke("events")[0].(<-chan string)
+	return
+}
+
+func (d *storeProviderDouble) account() (r *testAccount) {
+	d.TestDouble.T().Helper()
+	r, _ = d.Invoke("account")[0].(*testAccount)
+	return
+}
+
+func (d *storeProviderDouble) count() (r int) {
+	d.TestDouble.T().Helper()
+	r, _ = d.Invoke("count")[0].(int)
+	return
+}
+
+func (d *storeProviderDouble) err() (r error) {
+	d.TestDouble.T().Helper()
+	r, _ = d.Invoke("err")[0].(error)
+	return
+}
+
+func init() {
+	RegisterDouble((*store)(nil), func(d *TestDouble) interface{} { return &storeDouble{TestDouble: d} })
+}
+
+func stubAll(d *TestDouble) {
+	d.SetDefaultCall(func(m Method) MethodCall { return m.Stub() })
+}
+
+func TestSmartDefaults(t *testing.T) {
+	d := newStoreProviderDouble(t, SmartDefaults, stubAll)
+
+	if items := d.items(); items == nil || len(items) != 0 {
+		t.Errorf("Expected empty non nil slice, got %#v", items)
+	}
+	if limits := d.limits(); limits == nil || len(limits) != 0 {
+		t.Errorf("Expected empty non nil map, got %#v", limits)
+	}
+	if _, open := <-d.events(); open {
+		t.Errorf("Expected closed channel")
+	}
+	if account := d.account(); account == nil || account.ID != 0 {
+		t.Errorf("Expected pointer to zero struct, got %#v", account)
+	}
+	if count := d.count(); count != 0 {
+		t.Errorf("Expected zero, got %d", count)
+	}
+	if err := d.err(); err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+	}
+
+	d.limits()["x"] = 1
+	if limits := d.limits(); len(limits) != 0 {
+		t.Errorf("Expected a new map for each call, got %v", limits)
+	}
+}
+
+func TestTestDouble_Child(t *testing.T) {
+	d := newStoreProviderDouble(t, SmartDefaults, stubAll)
+	d.Child("store").Stub("get").Matching("k").Returning("v", nil)
+
+	s := d.store("a")
+	if v, err := s.get("k"); v != "v" || err != nil {
+		t.Errorf("Expected child stub to return v, got %v %v", v, err)
+	}
+	if keys := s.keys(); keys == nil {
+		t.Errorf("Expected child to inherit SmartDefaults, got nil keys")
+	}
+	if d.store("b") != s {
+		t.Errorf("Expected the same child for every call")
+	}
+	if child := d.Child("store"); child != s.(*storeDouble).TestDouble {
+		t.Errorf("Expected Child to return the double for the returned store")
+	}
+}
+
+func TestTestDouble_VerifiesChildren(t *testing.T) {
+	doubleT := NewTDouble(t)
+	spy := doubleT.Spy("Errorf")
+
+	d := newStoreProviderDouble(doubleT, SmartDefaults)
+	d.Child("store").Mock("get").Expect(Once())
+
+	d.Verify()
+
+	spy.Matching(printfMatcher("get")).Expect(Once())
+}
+
+func TestTestDouble_ChildFatallyFailsTheTest(t *testing.T) {
+	type test struct {
+		name        string
+		method      string
+		expectedMsg string
+	}
+
+	tests := []test{
+		{"NotAnInterface", "count", `Cannot get Child for method count .* registered double`},
+		{"NotRegistered", "err", `Cannot get Child for method err .* registered double`},
+		{"Missing", "missing", `non existent method missing`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			newStoreProviderDouble(tDouble, SmartDefaults).Child(test.method)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
@@ -17,10 +17,12 @@
 package godouble
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
-	"sync/atomic"
+	"time"
 )
 
 var tick uint64 //global atomic counter to assist with verifying order of execution
@@ -59,25 +61,71 @@ type RecordedCalls interface {
 
 		If necessary use NumCalls() to reference calls from the end of the slice.
 		eg to get the last 3 calls - r.Slice(r.NumCalls() -3, r.NumCalls())
+
+		See also First(), Last() and Nth() for individual calls.
 	*/
 	Slice(from int, to int) RecordedCalls
 
+	// First returns the subset containing only the first of these calls (empty if there are no calls)
+	First() RecordedCalls
+
+	// Last returns the subset containing only the last of these calls (empty if there are no calls)
+	Last() RecordedCalls
+
+	// Nth returns the subset containing only the call at index n (empty if n is out of range)
+	Nth(n int) RecordedCalls
+
 	// After returns the subset of these calls that were invoked after all of otherCalls
 	After(otherCalls RecordedCalls) RecordedCalls
 
+	/*
+		Each invokes f with the arguments of each of these calls, eg to make custom assertions
+
+		f must accept arguments compatible with the method signature and optionally return a bool.
+		Calls where f returns false are reported as errors.
+	*/
+	Each(f interface{})
+
+	/*
+		Where returns a CallsAssertion on the calls that match matchers
+
+		matchers are converted to a Matcher as per Matching()
+	*/
+	Where(matchers ...interface{}) CallsAssertion
+
 	// Expect asserts the number of calls in this set
 	Expect(expect Expectation)
 
+	/*
+		ExpectContextDone asserts the context.Context argument of each of these calls has since been cancelled or
+		expired, ie that the caller did not leak the context.
+
+		The test will fatally fail if the method does not take a context.Context argument.
+	*/
+	ExpectContextDone()
+
 	// NumCalls returns the number of calls in this set.
 	// Prefer to use Expect() rather than asserting the result of NumCalls()
 	NumCalls() int
 
 	calls() []*recordedCall
 	nested() []string
+	t() T
+}
+
+// CallsAssertion verifies how many of a set of RecordedCalls match a Matcher
+type CallsAssertion interface {
+	// All asserts every call matches (trivially true for no calls)
+	All()
+	// None asserts no call matches
+	None()
+	// Any asserts at least one call matches
+	Any()
 }
 
 type recordedCall struct {
-	tick uint64 //Record the order of all calls relative to each other.
+	tick uint64    //Record the order of all calls relative to each other.
+	at   time.Time //When the call was made according to the TestDouble's clock
 	args []interface{}
 }
 
@@ -133,11 +181,26 @@ func (c *spyMethodCall) Returning(values ...interface{}) SpyMethodCall {
 	return c
 }
 
-//Verify phase: expectations on call count
+//Verify phase: expectations on call count (and times)
 func (c *spyMethodCall) Expect(expect Expectation) {
-	count := c.NumCalls()
-	if !expect.Met(count) {
-		c.t().Errorf("%v expected %v, found %d calls", c, expect, count)
+	if !expectationMet(expect, c.receiver.started, c.recorded) {
+		c.t().Helper()
+		c.t().Errorf("%v expected %v, found %s", c, expect, describeCalls(expect, c.receiver.started, c.recorded))
+	}
+}
+
+//Verify phase: contexts passed to calls were cancelled
+func (c *spyMethodCall) ExpectContextDone() {
+	t := c.t()
+	t.Helper()
+	index, hasContext := contextArg(c.m.Type)
+	if !hasContext {
+		t.Fatalf("%v has no context.Context argument", c)
+	}
+	for _, call := range c.recorded {
+		if ctx, _ := call.args[index].(context.Context); ctx != nil && ctx.Err() == nil {
+			t.Errorf("%v\nexpected context passed at tick %d to be done, but it was not cancelled", c, call.tick)
+		}
 	}
 }
 
@@ -177,6 +240,115 @@ func (c *spyMethodCall) Slice(from int, to int) RecordedCalls {
 	return c.newSubset(subsetCalls, fmt.Sprintf("newSliceMatcher%s of", sliceDesc))
 }
 
+func (c *spyMethodCall) First() RecordedCalls {
+	return c.nth(0, "first call of")
+}
+
+func (c *spyMethodCall) Last() RecordedCalls {
+	return c.nth(len(c.recorded)-1, "last call of")
+}
+
+func (c *spyMethodCall) Nth(n int) RecordedCalls {
+	if n < 0 {
+		c.t().Fatalf("Invalid Nth(%d) of RecordedCalls %v", n, c)
+	}
+	return c.nth(n, fmt.Sprintf("call[%d] of", n))
+}
+
+func (c *spyMethodCall) nth(n int, desc string) RecordedCalls {
+	var subsetCalls []*recordedCall
+	if n >= 0 && n < len(c.recorded) {
+		subsetCalls = c.recorded[n : n+1]
+	}
+	return c.newSubset(subsetCalls, desc)
+}
+
+func (c *spyMethodCall) Each(f interface{}) {
+	t := c.t()
+	t.Helper()
+	fv := reflect.ValueOf(f)
+	ft := reflect.TypeOf(f)
+	AssertMethodInputs(t, c.m, ft)
+	if ft.NumOut() > 1 || (ft.NumOut() == 1 && ft.Out(0).Kind() != reflect.Bool) {
+		t.Fatalf("expected Each(%v) to return nothing or bool", ft)
+	}
+
+	for _, call := range c.recorded {
+		inArgs := make([]reflect.Value, len(call.args))
+		for i, arg := range call.args {
+			inArgs[i] = reflect.ValueOf(arg)
+			if !inArgs[i].IsValid() && i < ft.NumIn() {
+				//untyped nil, eg from a nil interface
+				inArgs[i] = reflect.Zero(ft.In(i))
+			}
+		}
+		var results []reflect.Value
+		if ft.IsVariadic() {
+			results = fv.CallSlice(inArgs)
+		} else {
+			results = fv.Call(inArgs)
+		}
+		if len(results) == 1 && !results[0].Bool() {
+			t.Errorf("%v\nexpected call at tick %d with args %v to satisfy %v", c, call.tick, call.args, ft)
+		}
+	}
+}
+
+func (c *spyMethodCall) Where(matchers ...interface{}) CallsAssertion {
+	return &callsAssertion{c, c.receiver.matcher(c.t(), c.m, nil, matchers...)}
+}
+
+type callsAssertion struct {
+	*spyMethodCall
+	matcher MethodArgsMatcher
+}
+
+func (a *callsAssertion) partition() (matched []*recordedCall, unmatched []*recordedCall) {
+	for _, call := range a.recorded {
+		if a.matcher.Matches(call.args...) {
+			matched = append(matched, call)
+		} else {
+			unmatched = append(unmatched, call)
+		}
+	}
+	return
+}
+
+func (a *callsAssertion) describe(calls []*recordedCall, explain bool) string {
+	sb := strings.Builder{}
+	for _, call := range calls {
+		fmt.Fprintf(&sb, "\n  tick %d: %v", call.tick, call.args)
+		if !explain {
+			continue
+		}
+		if matched, explanation := Explain(a.matcher, call.args...); !matched {
+			fmt.Fprintf(&sb, "\n    %s", indent(explanation, "    "))
+		}
+	}
+	return sb.String()
+}
+
+func (a *callsAssertion) All() {
+	if _, unmatched := a.partition(); len(unmatched) > 0 {
+		a.t().Helper()
+		a.t().Errorf("%v\nexpected all calls to match %v, found %d that did not:%s", a.spyMethodCall, a.matcher, len(unmatched), a.describe(unmatched, true))
+	}
+}
+
+func (a *callsAssertion) None() {
+	if matched, _ := a.partition(); len(matched) > 0 {
+		a.t().Helper()
+		a.t().Errorf("%v\nexpected no calls to match %v, found %d that did:%s", a.spyMethodCall, a.matcher, len(matched), a.describe(matched, false))
+	}
+}
+
+func (a *callsAssertion) Any() {
+	if matched, unmatched := a.partition(); len(matched) == 0 {
+		a.t().Helper()
+		a.t().Errorf("%v\nexpected any call to match %v, found none in %d calls:%s", a.spyMethodCall, a.matcher, len(unmatched), a.describe(unmatched, true))
+	}
+}
+
 //Return the calls in c that occurred after those in calls
 func (c *spyMethodCall) After(recordedCalls RecordedCalls) RecordedCalls {
 	recorded := recordedCalls.calls()
@@ -197,6 +369,67 @@ func (c *spyMethodCall) After(recordedCalls RecordedCalls) RecordedCalls {
 	return c.newSubset(subsetCalls, nested...)
 }
 
+/*
+VerifyBefore asserts that all the calls in before were invoked before any of the calls in after.
+
+Either set may be on any method of any double. Empty sets are trivially in order.
+*/
+func VerifyBefore(before RecordedCalls, after RecordedCalls) {
+	t := before.t()
+	t.Helper()
+	verifyBefore(t, before, after)
+}
+
+/*
+VerifyInOrder asserts that all the calls in each set were invoked before any of the calls in the following sets.
+
+Empty sets are ignored.
+*/
+func VerifyInOrder(sets ...RecordedCalls) {
+	var previous RecordedCalls
+	for _, set := range sets {
+		if len(set.calls()) == 0 {
+			continue
+		}
+		if previous != nil {
+			t := previous.t()
+			t.Helper()
+			verifyBefore(t, previous, set)
+		}
+		previous = set
+	}
+}
+
+func verifyBefore(t T, before RecordedCalls, after RecordedCalls) {
+	t.Helper()
+	beforeCalls, afterCalls := before.calls(), after.calls()
+	if len(beforeCalls) == 0 || len(afterCalls) == 0 {
+		return
+	}
+	firstAfter, lastBefore := afterCalls[0].tick, beforeCalls[len(beforeCalls)-1].tick
+	if lastBefore < firstAfter {
+		return
+	}
+
+	var lateTicks, earlyTicks []uint64
+	for _, call := range beforeCalls {
+		if call.tick > firstAfter {
+			lateTicks = append(lateTicks, call.tick)
+		}
+	}
+	for _, call := range afterCalls {
+		if call.tick < lastBefore {
+			earlyTicks = append(earlyTicks, call.tick)
+		}
+	}
+	t.Errorf("expected calls\n  %s\nat ticks %v\nto be before calls\n  %s\nat ticks %v",
+		indent(fmt.Sprint(before), "  "), lateTicks, indent(fmt.Sprint(after), "  "), earlyTicks)
+}
+
+func indent(s string, prefix string) string {
+	return strings.Replace(s, "\n", "\n"+prefix, -1)
+}
+
 func newSpyMethodCall(m *method, subsets ...string) *spyMethodCall {
 
 	if len(subsets) == 0 {
@@ -222,12 +455,12 @@ func (c *spyMethodCall) matches(_ []interface{}) bool {
 	return true
 }
 
-func (c *spyMethodCall) spy(args []interface{}) ([]interface{}, error) {
-	//Spy happens within a method mutex so this is safe..
-	c.recorded = append(c.recorded, newRecordedCall(args))
-	return c.stubbedMethodCall.spy(args)
+func (c *spyMethodCall) explain(_ []interface{}) (bool, string) {
+	return true, ""
 }
 
-func newRecordedCall(args []interface{}) *recordedCall {
-	return &recordedCall{args: args, tick: atomic.AddUint64(&tick, 1)}
+func (c *spyMethodCall) spy(args []interface{}) ReturnValues {
+	//Spy happens within a method mutex so this is safe..
+	c.recorded = append(c.recorded, c.newRecordedCall(args))
+	return c.stubbedMethodCall.spy(args)
 }
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestVerifyInOrder(t *testing.T) {
+	d1 := newApiDouble(t)
+	d2 := newApiDouble(t)
+	calls := d1.Spy("call")
+	others := d2.Spy("other")
+	empties := d1.Spy("empty")
+
+	d1.call("open")
+	d1.call("write")
+	d2.other()
+	d1.call("write")
+	d1.call("close")
+
+	open, write, other, closed := calls.Matching("open"), calls.Matching("write"), others, calls.Matching("close")
+
+	VerifyBefore(open, write)
+	VerifyBefore(write, closed)
+	VerifyBefore(empties, open)
+	VerifyBefore(open, empties)
+	VerifyInOrder(open, write, empties, closed)
+	VerifyInOrder(open, other, closed)
+}
+
+func TestVerifyInOrder_ReportsOffendingTicks(t *testing.T) {
+	doubleT := NewTDouble(t, func(c *TestDouble) {
+		//c.EnableTrace()
+	})
+	spy := doubleT.Spy("Errorf")
+
+	d1 := newApiDouble(doubleT, func(c *TestDouble) { c.DisableTrace() })
+	calls := d1.Spy("call")
+	d1.call("write")
+	d1.call("open")
+	d1.call("write")
+	d1.call("close")
+
+	open, write, closed := calls.Matching("open"), calls.Matching("write"), calls.Matching("close")
+	ticks := make([]string, 4)
+	for i, call := range calls.calls() {
+		ticks[i] = fmt.Sprint(call.tick)
+	}
+
+	VerifyBefore(open, write)
+	spy.Matching(printfMatcher(`(?s)expected calls\n  calls matching.*open.*\n    .*call\nat ticks \[` + ticks[1] + `\]\nto be before calls\n  calls matching.*write.*\nat ticks \[` + ticks[0] + `\]$`)).Expect(Once())
+
+	VerifyInOrder(open, closed, write)
+	spy.Matching(printfMatcher(`(?s)expected.*close.*ticks \[` + ticks[3] + `\].*before.*write.*ticks \[` + ticks[0] + ` ` + ticks[2] + `\]$`)).Expect(Once())
+
+	spy.Expect(Twice())
+}
+
+func TestRecordedCalls_FirstLastNth(t *testing.T) {
+	d1 := newApiDouble(t)
+	spy := d1.Spy("call")
+	empty := d1.Spy("empty")
+
+	for _, s := range []string{"one", "two", "three"} {
+		d1.call(s)
+	}
+
+	spy.First().Matching("one").Expect(Once())
+	spy.Last().Matching("three").Expect(Once())
+	spy.Nth(1).Matching("two").Expect(Once())
+	spy.Nth(3).Expect(Never())
+	empty.First().Expect(Never())
+	empty.Last().Expect(Never())
+	spy.Matching(Not(Eql("one"))).First().Matching("two").Expect(Once())
+
+	assertMatch(t, spy.Last(), `(?s)^last call of\n  .*call$`)
+	assertMatch(t, spy.Nth(2), `(?s)^call\[2\] of\n  .*call$`)
+}
+
+func TestRecordedCalls_Each(t *testing.T) {
+	doubleT := NewTDouble(t, func(c *TestDouble) {
+		//c.EnableTrace()
+	})
+	spy := doubleT.Spy("Errorf")
+
+	d1 := newApiDouble(doubleT, func(c *TestDouble) { c.DisableTrace() })
+	calls := d1.Spy("test")
+	_, _ = d1.test(1, "one")
+	_, _ = d1.test(2, "two")
+
+	var seen []string
+	calls.Each(func(i int, s string) { seen = append(seen, fmt.Sprint(i, "-", s)) })
+	if fmt.Sprint(seen) != "[1-one 2-two]" {
+		t.Errorf("Expected Each to see all calls in order, got %v", seen)
+	}
+
+	calls.Each(func(i int, s string) bool { return len(s) == 3 })
+	spy.Expect(Never())
+
+	calls.Each(func(i int, s string) bool { return i < 2 })
+	spy.Matching(printfMatcher(`(?s)^.*test\nexpected call at tick \d+ with args \[2 two\] to satisfy func\(int, string\) bool$`)).Expect(Once())
+}
+
+func TestRecordedCalls_EachWithNilArgs(t *testing.T) {
+	d := newReporterDouble(t)
+	reports := d.Spy("report")
+	d.report(nil)
+	d.report(errors.New("failed"))
+
+	var seen []error
+	reports.Each(func(err error) bool {
+		seen = append(seen, err)
+		return true
+	})
+	if len(seen) != 2 || seen[0] != nil || seen[1] == nil {
+		t.Errorf("Expected Each to see nil then an error, got %v", seen)
+	}
+}
+
+func TestRecordedCalls_EachFailsFatally(t *testing.T) {
+	tests := []struct {
+		name        string
+		f           interface{}
+		expectedMsg string
+	}{
+		{"BadArgs", func(s string) {}, "arguments"},
+		{"BadReturn", func(i int, s string) int { return 0 }, "nothing or bool"},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			newApiDouble(tDouble).Spy("test").Each(test.f)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
+
+func TestRecordedCalls_Where(t *testing.T) {
+	doubleT := NewTDouble(t, func(c *TestDouble) {
+		//c.EnableTrace()
+	})
+	spy := doubleT.Spy("Errorf")
+
+	d1 := newApiDouble(doubleT, func(c *TestDouble) { c.DisableTrace() })
+	calls := d1.Spy("call")
+	for _, s := range []string{"one", "two", "three"} {
+		d1.call(s)
+	}
+
+	calls.Where(Len(3)).Any()
+	calls.Where(Len(4)).None()
+	calls.Where(Len(Func(func(l int) bool { return l >= 3 }))).All()
+	calls.Slice(0, 2).Where(Len(3)).All()
+	d1.Spy("empty").Where(Any()).All()
+	spy.Expect(Never())
+
+	calls.Where(Len(3)).All()
+	spy.Matching(printfMatcher(`(?s)^.*call\nexpected all calls to match Args\(Len\(Eql\(3\)\)\), found 1 that did not:\n  tick \d+: \[three\]\n    arg\[0\]: len\(\): 5 did not match Eql\(3\)$`)).Expect(Once())
+
+	calls.Where(Len(3)).None()
+	spy.Matching(printfMatcher(`(?s)expected no calls.*found 2 that did:\n  tick \d+: \[one\]\n  tick \d+: \[two\]$`)).Expect(Once())
+
+	calls.Last().Where(Len(3)).Any()
+	spy.Matching(printfMatcher(`(?s)^last call of\n.*expected any call.*found none in 1 calls:\n  tick \d+: \[three\]\n    arg\[0\]: len\(\): 5 did not match Eql\(3\)$`)).Expect(Once())
+}
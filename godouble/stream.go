@@ -0,0 +1,383 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type streamState int
+
+const (
+	streaming streamState = iota
+	drained
+	cancelled
+	stopped //by Verify
+)
+
+// stream is the goroutine feeding the channel returned from one invocation
+type stream struct {
+	number int
+	sent   int
+	state  streamState
+	err    error
+	stop   chan struct{}
+	ctx    context.Context //of the invocation, nil if it had no context argument
+}
+
+func (s *stream) String() string {
+	return fmt.Sprintf("stream %d", s.number)
+}
+
+type streamReturnValues struct {
+	mutex    *sync.Mutex
+	desc     string
+	events   func() func() (event interface{}, ok bool, err error) //creates the source of events for each stream
+	fixed    []interface{}                                         //events known up front, checked by ForMethod
+	t        T
+	method   reflect.Method
+	result   int             //index of the channel or iterator result
+	iterator *streamIterator //nil for a channel result
+	streams  []*stream
+}
+
+// streamIterator is an iterator interface with a registered double (see RegisterDouble), eg
+//  type Iterator interface {
+//    Next() bool
+//    Event() Event    // the single other method without arguments returning one value
+//    Err() error      // optional
+//    Close()          // optional, may return an error
+//  }
+type streamIterator struct {
+	forInterface reflect.Type
+	wrap         func(*TestDouble) interface{}
+	value        reflect.Method
+	hasErr       bool
+	close        *reflect.Method
+}
+
+// newStreamIterator is the streamIterator for out, if it is an iterator interface with a registered double
+func newStreamIterator(out reflect.Type) (*streamIterator, bool) {
+	if out.Kind() != reflect.Interface {
+		return nil, false
+	}
+	wrap, registered := registeredDouble(out)
+	if next, hasNext := out.MethodByName("Next"); !registered || !hasNext || next.Type != reflect.TypeOf(func() bool { return false }) {
+		return nil, false
+	}
+
+	it := &streamIterator{forInterface: out, wrap: wrap}
+	values := 0
+	for i := 0; i < out.NumMethod(); i++ {
+		m := out.Method(i)
+		switch {
+		case m.Name == "Next":
+		case m.Name == "Err" && m.Type == reflect.TypeOf(func() error { return nil }):
+			it.hasErr = true
+		case m.Name == "Close" && m.Type.NumIn() == 0:
+			it.close = &m
+		case m.Type.NumIn() == 0 && m.Type.NumOut() == 1:
+			it.value = m
+			values++
+		}
+	}
+	return it, values == 1
+}
+
+/*
+Stream returns a new channel for each invocation of a method with a channel result, eg
+ Subscribe(ctx context.Context) (<-chan Event, error)
+
+A goroutine sends events on the channel, and closes it after the last event. If the invocation has a context.Context
+argument, the stream stops and the channel is closed when the context is done.
+
+A method can instead return an iterator interface with a registered double (see RegisterDouble), eg
+ List() Iterator
+where Iterator has Next() bool and a single other method without arguments returning the current event, and
+optionally Err() error and Close(). Each invocation returns a new iterator double. Close() cancels the stream.
+
+Other results are zero values. Verify fails the test for streams that were neither drained nor cancelled, and stops them.
+*/
+func Stream(events ...interface{}) VerifiableReturnValues {
+	return newStreamReturnValues(fmt.Sprintf("Stream%v", events), events, func() func() (interface{}, bool, error) {
+		next := 0
+		return func() (interface{}, bool, error) {
+			if next >= len(events) {
+				return nil, false, nil
+			}
+			next++
+			return events[next-1], true, nil
+		}
+	})
+}
+
+/*
+StreamFrom is a Stream of the first value of each of the values received from source (eg a ReturnChannel),
+until source returns an error (eg because it was closed). Concurrent streams share the values from source.
+
+If source times out waiting for values the stream stops, and fails when verified.
+*/
+func StreamFrom(source ReturnValues) VerifiableReturnValues {
+	return newStreamReturnValues(fmt.Sprintf("StreamFrom(%v)", source), nil, func() func() (interface{}, bool, error) {
+		return func() (interface{}, bool, error) {
+			values, err := source.Receive()
+			if isTimeout(err) {
+				return nil, false, err
+			}
+			if err != nil || len(values) == 0 {
+				return nil, false, nil
+			}
+			return values[0], true, nil
+		}
+	})
+}
+
+func newStreamReturnValues(desc string, fixed []interface{}, events func() func() (interface{}, bool, error)) *streamReturnValues {
+	return &streamReturnValues{mutex: &sync.Mutex{}, desc: desc, events: events, fixed: fixed, result: -1}
+}
+
+func (s *streamReturnValues) String() string {
+	return s.desc
+}
+
+func (s *streamReturnValues) ForMethod(t T, m reflect.Method) {
+	t.Helper()
+	for i := 0; i < m.Type.NumOut() && s.result < 0; i++ {
+		out := m.Type.Out(i)
+		if out.Kind() == reflect.Chan && out.ChanDir()&reflect.RecvDir != 0 {
+			s.t, s.method, s.result = t, m, i
+		} else if it, isIterator := newStreamIterator(out); isIterator {
+			s.t, s.method, s.result, s.iterator = t, m, i, it
+		}
+	}
+	if s.result < 0 {
+		t.Fatalf("%v for %v expects a channel result, or an iterator interface with a registered double", s, m.Type)
+	}
+
+	elem := s.elem()
+	for _, event := range s.fixed {
+		if !assignable(event, elem) {
+			t.Fatalf("%v for %v cannot stream %#v as %v", s, m.Type, event, elem)
+		}
+	}
+}
+
+// elem is the type of the events of the channel or iterator result
+func (s *streamReturnValues) elem() reflect.Type {
+	if s.iterator != nil {
+		return s.iterator.value.Type.Out(0)
+	}
+	return s.method.Type.Out(s.result).Elem()
+}
+
+// assignable is whether event can be sent on a channel of elem
+func assignable(event interface{}, elem reflect.Type) bool {
+	if event == nil {
+		switch elem.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			return true
+		}
+		return false
+	}
+	return reflect.TypeOf(event).AssignableTo(elem)
+}
+
+// eventValue is the value of an assignable event as an elem
+func eventValue(event interface{}, elem reflect.Type) reflect.Value {
+	if event == nil {
+		return reflect.Zero(elem)
+	}
+	return reflect.ValueOf(event).Convert(elem)
+}
+
+func (s *streamReturnValues) Receive() ([]interface{}, error) {
+	return s.ReceiveArgs(nil)
+}
+
+// ReceiveArgs starts a new stream, which is cancelled if args include a context.Context that is done
+func (s *streamReturnValues) ReceiveArgs(args []interface{}) ([]interface{}, error) {
+	if s.result < 0 {
+		return nil, errors.New("stream has not been configured for a method")
+	}
+	var ctx context.Context
+	for _, arg := range args {
+		if argCtx, isCtx := arg.(context.Context); isCtx && argCtx != nil {
+			ctx = argCtx
+			break
+		}
+	}
+
+	s.mutex.Lock()
+	st := &stream{number: len(s.streams) + 1, stop: make(chan struct{}), ctx: ctx}
+	s.streams = append(s.streams, st)
+	s.mutex.Unlock()
+
+	results := make([]interface{}, s.method.Type.NumOut())
+	for i := range results {
+		results[i] = reflect.Zero(s.method.Type.Out(i)).Interface()
+	}
+
+	if s.iterator != nil {
+		results[s.result] = s.newIterator(st, ctx)
+		return results, nil
+	}
+
+	out := s.method.Type.Out(s.result)
+	c := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, out.Elem()), 0)
+	go s.feed(st, c, ctx)
+	results[s.result] = c.Convert(out).Interface()
+	return results, nil
+}
+
+// newIterator creates an iterator double that returns the events of st from Next() and its value method
+func (s *streamReturnValues) newIterator(st *stream, ctx context.Context) interface{} {
+	it := s.iterator
+	elem := s.elem()
+	next := s.events()
+	current := reflect.Zero(elem)
+	var err error
+
+	double := NewDouble(s.t, reflect.Zero(reflect.PtrTo(it.forInterface)).Interface())
+	double.Fake("Next", func() bool {
+		if ctx != nil && ctx.Err() != nil {
+			s.finish(st, cancelled, nil)
+		}
+		if !s.streaming(st) {
+			return false
+		}
+		event, ok, nextErr := next()
+		switch {
+		case nextErr != nil:
+			err = nextErr
+		case !ok:
+			s.finish(st, drained, nil)
+			return false
+		case !assignable(event, elem):
+			err = fmt.Errorf("cannot stream %#v as %v", event, elem)
+		}
+		if err != nil {
+			s.finish(st, stopped, err)
+			return false
+		}
+		current = eventValue(event, elem)
+		s.mutex.Lock()
+		st.sent++
+		s.mutex.Unlock()
+		return true
+	})
+	double.Fake(it.value.Name, reflect.MakeFunc(it.value.Type, func([]reflect.Value) []reflect.Value {
+		return []reflect.Value{current}
+	}).Interface())
+	if it.hasErr {
+		double.Fake("Err", func() error { return err })
+	}
+	if it.close != nil {
+		double.Fake("Close", reflect.MakeFunc(it.close.Type, func([]reflect.Value) []reflect.Value {
+			s.finish(st, cancelled, nil)
+			results := make([]reflect.Value, it.close.Type.NumOut())
+			for i := range results {
+				results[i] = reflect.Zero(it.close.Type.Out(i))
+			}
+			return results
+		}).Interface())
+	}
+	return it.wrap(double)
+}
+
+// feed sends events on c until there are no more, ctx is done or the stream is stopped, and then closes c
+func (s *streamReturnValues) feed(st *stream, c reflect.Value, ctx context.Context) {
+	defer c.Close()
+	elem := c.Type().Elem()
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: c},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(st.stop)},
+	}
+	if ctx != nil {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+	}
+
+	next := s.events()
+	for {
+		event, ok, err := next()
+		if err != nil {
+			s.finish(st, stopped, err)
+			return
+		}
+		if !ok {
+			s.finish(st, drained, nil)
+			return
+		}
+		if !assignable(event, elem) {
+			s.finish(st, stopped, fmt.Errorf("cannot send %#v on chan %v", event, elem))
+			return
+		}
+		cases[0].Send = eventValue(event, elem)
+
+		switch chosen, _, _ := reflect.Select(cases); chosen {
+		case 0:
+			s.mutex.Lock()
+			st.sent++
+			s.mutex.Unlock()
+		case 1:
+			return //state set by Verify
+		default:
+			s.finish(st, cancelled, nil)
+			return
+		}
+	}
+}
+
+func (s *streamReturnValues) streaming(st *stream) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return st.state == streaming
+}
+
+func (s *streamReturnValues) finish(st *stream, state streamState, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if st.state == streaming {
+		st.state, st.err = state, err
+	}
+}
+
+// Verify errors for each stream that was neither drained nor cancelled, and stops it
+//
+// A stream whose context is done is cancelled, even if its goroutine has not yet seen that.
+func (s *streamReturnValues) Verify(t T) {
+	t.Helper()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, st := range s.streams {
+		switch {
+		case st.err != nil:
+			t.Errorf("%v %v failed after %d events: %v", s, st, st.sent, st.err)
+		case st.state == streaming && st.ctx != nil && st.ctx.Err() != nil:
+			st.state = cancelled
+			close(st.stop)
+		case st.state == streaming:
+			t.Errorf("%v %v was neither drained nor cancelled after %d events", s, st, st.sent)
+			st.state = stopped
+			close(st.stop)
+		}
+	}
+}
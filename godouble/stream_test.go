@@ -0,0 +1,295 @@
+/*
+ * Copyright 2020 grant@lastweekend.com.au
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package godouble
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type subscriber interface {
+	subscribe(ctx context.Context) (<-chan string, error)
+	list(ctx context.Context) stringIterator
+	count() int
+}
+
+type stringIterator interface {
+	Next() bool
+	Value() string
+	Err() error
+	Close()
+}
+
+type stringIteratorDouble struct {
+	stringIterator
+	*TestDouble
+}
+
+func init() {
+	RegisterDouble((*stringIterator)(nil), func(d *TestDouble) interface{} { return &stringIteratorDouble{TestDouble: d} })
+}
+
+func (d *stringIteratorDouble) Next() bool {
+	d.TestDouble.T().Helper()
+	return d.Invoke("Next")[0].(bool)
+}
+
+func (d *stringIteratorDouble) Value() string {
+	d.TestDouble.T().Helper()
+	return d.Invoke("Value")[0].(string)
+}
+
+func (d *stringIteratorDouble) Err() (e error) {
+	d.TestDouble.T().Helper()
+	e, _ = d.Invoke("Err")[0].(error)
+	return
+}
+
+func (d *stringIteratorDouble) Close() {
+	d.TestDouble.T().Helper()
+	d.Invoke("Close")
+}
+
+type subscriberDouble struct {
+	subscriber
+	*TestDouble
+}
+
+func newSubscriberDouble(t T, configurators ...func(*TestDouble)) *subscriberDouble {
+	return &subscriberDouble{TestDouble: NewDouble(t, (*subscriber)(nil), configurators...)}
+}
+
+func (d *subscriberDouble) list(ctx context.Context) (r stringIterator) {
+	d.TestDouble.T().Helper()
+	r, _ = d.Invoke("list", ctx)[0].(stringIterator)
+	return
+}
+
+func (d *subscriberDouble) subscribe(ctx context.Context) (r <-chan string, e error) {
+	d.TestDouble.T().Helper()
+	returns := d.Invoke("subscribe", ctx)
+	r, _ = returns[0].(<-chan string)
+	e, _ = returns[1].(error)
+	return
+}
+
+// drain receives events from c until it is closed, failing if it is not closed promptly
+func drain(t *testing.T, c <-chan string) []string {
+	t.Helper()
+	var events []string
+	for {
+		select {
+		case event, open := <-c:
+			if !open {
+				return events
+			}
+			events = append(events, event)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected stream to be closed, received %v", events)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	d := newSubscriberDouble(t)
+	defer d.Verify()
+	d.Stub("subscribe").Returning(Stream("a", "b"))
+
+	first, err := d.subscribe(context.Background())
+	second, _ := d.subscribe(context.Background())
+	if err != nil || first == second {
+		t.Errorf("Expected a new channel for each call, got %v, %v", first, err)
+	}
+	for _, c := range []<-chan string{second, first} {
+		if events := drain(t, c); !reflect.DeepEqual(events, []string{"a", "b"}) {
+			t.Errorf("Expected every stream to send a, b, got %v", events)
+		}
+	}
+}
+
+// iterate returns the values from it until Next() returns false
+func iterate(it stringIterator) []string {
+	var values []string
+	for it.Next() {
+		values = append(values, it.Value())
+	}
+	return values
+}
+
+func TestStream_Iterator(t *testing.T) {
+	d := newSubscriberDouble(t, func(d *TestDouble) { d.DisableTrace() })
+	defer d.Verify()
+	d.Stub("list").Returning(Stream("a", "b"))
+
+	first, second := d.list(context.Background()), d.list(context.Background())
+	for _, it := range []stringIterator{second, first} {
+		if values := iterate(it); !reflect.DeepEqual(values, []string{"a", "b"}) || it.Err() != nil {
+			t.Errorf("Expected every iterator to return a, b, got %v, %v", values, it.Err())
+		}
+	}
+
+	closed := d.list(context.Background())
+	closed.Next()
+	closed.Close()
+	if closed.Next() {
+		t.Errorf("Expected no more values after Close")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := d.list(ctx)
+	cancelled.Next()
+	cancel()
+	if cancelled.Next() {
+		t.Errorf("Expected no more values after the context is cancelled")
+	}
+}
+
+func TestStream_Cancelled(t *testing.T) {
+	d := newSubscriberDouble(t)
+	defer d.Verify()
+	d.Stub("subscribe").Returning(Stream("a", "b"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c, _ := d.subscribe(ctx)
+	if event := <-c; event != "a" {
+		t.Errorf("Expected a, got %v", event)
+	}
+	cancel()
+	drain(t, c)
+}
+
+func TestStream_CancelledWithoutDraining(t *testing.T) {
+	d := newSubscriberDouble(t)
+	d.Stub("subscribe").Returning(Stream("a", "b"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c, _ := d.subscribe(ctx)
+	if event := <-c; event != "a" {
+		t.Errorf("Expected a, got %v", event)
+	}
+	cancel()
+	d.Verify()
+}
+
+func TestStreamFrom(t *testing.T) {
+	d := newSubscriberDouble(t)
+	defer d.Verify()
+
+	rc := NewReturnChannel(3)
+	d.Stub("subscribe").Returning(StreamFrom(rc))
+	rc.Send("x")
+	rc.Send("y")
+	rc.Close()
+
+	c, _ := d.subscribe(nil)
+	if events := drain(t, c); !reflect.DeepEqual(events, []string{"x", "y"}) {
+		t.Errorf("Expected x, y, got %v", events)
+	}
+}
+
+func TestStreamFrom_VerifyErrorsForTimedOutSource(t *testing.T) {
+	doubleT := NewTDouble(t)
+	spy := doubleT.Spy("Errorf")
+
+	d := newSubscriberDouble(doubleT)
+	rc := NewReturnChannel(1)
+	rc.SetTimeout(10 * time.Millisecond) //x is received before the timeout expires
+	d.Stub("subscribe").Returning(StreamFrom(rc))
+	rc.Send("x")
+
+	c, _ := d.subscribe(nil)
+	if events := drain(t, c); !reflect.DeepEqual(events, []string{"x"}) {
+		t.Errorf("Expected x, got %v", events)
+	}
+	d.Verify()
+
+	spy.Matching(printfMatcher(`^StreamFrom\(.*\) stream 1 failed after 1 events: timed out waiting for return channel`)).Expect(Once())
+}
+
+func TestStreamFrom_IteratorErrForTimedOutSource(t *testing.T) {
+	doubleT := NewTDouble(t)
+	spy := doubleT.Spy("Errorf")
+
+	d := newSubscriberDouble(doubleT, func(d *TestDouble) { d.DisableTrace() })
+	rc := NewReturnChannel(1)
+	rc.SetTimeout(10 * time.Millisecond) //x is received before the timeout expires
+	d.Stub("list").Returning(StreamFrom(rc))
+	rc.Send("x")
+
+	it := d.list(nil)
+	if values := iterate(it); !reflect.DeepEqual(values, []string{"x"}) || it.Err() == nil {
+		t.Errorf("Expected x then a timeout error, got %v, %v", values, it.Err())
+	}
+	d.Verify()
+
+	spy.Matching(printfMatcher(`stream 1 failed after 1 events: timed out`)).Expect(Once())
+}
+
+func TestStream_VerifyErrorsForUnfinishedStreams(t *testing.T) {
+	doubleT := NewTDouble(t)
+	spy := doubleT.Spy("Errorf")
+
+	d := newSubscriberDouble(doubleT)
+	d.Stub("subscribe").Returning(Stream("a", "b", "c"))
+	c, _ := d.subscribe(context.Background())
+	<-c
+
+	d.Stub("list").Returning(Stream("a", "b", "c"))
+	it := d.list(context.Background())
+	it.Next()
+
+	d.Verify()
+	drain(t, c)
+	if it.Next() {
+		t.Errorf("Expected iterator to be stopped by Verify")
+	}
+
+	spy.Matching(printfMatcher(`^Stream\[a b c\] stream 1 was neither drained nor cancelled after 1 events$`)).Expect(Twice())
+}
+
+func TestStream_FatallyFailsTheTest(t *testing.T) {
+	type test struct {
+		name        string
+		method      string
+		rv          ReturnValues
+		expectedMsg string
+	}
+
+	tests := []test{
+		{"NoChannel", "count", Stream(1), `Stream\[1\] for func\(\) int expects a channel result`},
+		{"WrongEvent", "subscribe", Stream("a", 1), `cannot stream 1 as string`},
+		{"WrongIteratorEvent", "list", Stream("a", 1), `cannot stream 1 as string`},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			tDouble := NewTDouble(t)
+			spy := tDouble.Fake("Fatalf", tDouble.FakeFatalf)
+			defer func(spy FakeMethodCall) {
+				recover()
+				spy.Matching(printfMatcher(test.expectedMsg)).Expect(Once())
+			}(spy)
+
+			m, _ := reflect.TypeOf((*subscriber)(nil)).Elem().MethodByName(test.method)
+			NewReturnsForMethod(tDouble, m, test.rv)
+			t.Errorf("Expect unreachable")
+		})
+	}
+}
@@ -58,15 +58,28 @@ func (c *stubbedMethodCall) matches(args []interface{}) bool {
 	return true
 }
 
-func (c *stubbedMethodCall) spy(_ []interface{}) ([]interface{}, error) {
+func (c *stubbedMethodCall) explain(args []interface{}) (bool, string) {
+	if c.matcher != nil {
+		return Explain(c.matcher, args...)
+	}
+	return true, ""
+}
+
+func (c *stubbedMethodCall) spy(args []interface{}) ReturnValues {
 	if c.returns == nil {
 		c.returns = c.receiver.defaultReturnValues(c.method)
 	}
-	return c.returns.Receive()
+	if argsRV, hasArgs := c.returns.(ArgsReturnValues); hasArgs {
+		return boundReturnValues{argsRV, args}
+	}
+	return c.returns
 }
 
-func (c *stubbedMethodCall) verify(T) {
-	//Nothing to verify
+func (c *stubbedMethodCall) verify(t T) {
+	t.Helper()
+	if verifiableRV, isVerifiable := c.returns.(VerifiableReturnValues); isVerifiable {
+		verifiableRV.Verify(t)
+	}
 }
 
 func newStubbedMethodCall(m *method) (call *stubbedMethodCall) {